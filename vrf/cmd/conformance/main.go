@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/conformance"
+)
+
+// conformance writes the JSON conformance suite clients in other languages verify against,
+// vrf/test/testdata/conformance.json is the committed copy checked by the go tests
+func main() {
+	outputs := flag.Int("n", conformance.DEFAULT_OUTPUTS, "outputs recorded per generator method")
+	out := flag.String("out", "conformance.json", "suite file to write, - for stdout")
+	flag.Parse()
+
+	suite, err := conformance.Generate(*outputs)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	b, err := json.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	b = append(b, '\n')
+
+	if *out == "-" {
+		_, err = os.Stdout.Write(b)
+	} else {
+		err = os.WriteFile(*out, b, 0644)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,423 @@
+// Package conformance builds the conformance suite other implementations of the SDK check themselves against:
+// entropy encodings, VRF inputs and outputs of test keys, derived seeds and the outputs of every trand generator,
+// game helper and distribution. The suite is deterministic, vrf/cmd/conformance writes it and vrf/test checks the committed copy against the code.
+package conformance
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf/entropy"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+
+	"github.com/minio/blake2b-simd"
+	"golang.org/x/xerrors"
+)
+
+// SUITE_VERSION is the version of the suite format
+const SUITE_VERSION = 1
+
+// DEFAULT_OUTPUTS is the number of outputs recorded per generator method
+const DEFAULT_OUTPUTS = 16
+
+// Suite is the conformance suite, every byte string is hex and every 64 bits integer is a decimal string
+// since JSON numbers lose precision above 2^53 in most languages
+type Suite struct {
+	Version     int
+	Description string
+	Outputs     int // outputs per generator method
+	Entropy     []EntropyVector
+	VRF         []VRFVector
+	Derive      []DeriveVector
+	Rng         []RngVector
+}
+
+// EntropyField is an extra field of a round, Value is its canonical encoding
+type EntropyField struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// EntropyVector is a round and its entropy.Round.Encode encoding
+type EntropyVector struct {
+	GameID    string
+	RoundID   string
+	ReplayID  string
+	PlayerIDs []string
+	Fields    []EntropyField
+	Encoded   string
+}
+
+// VRFVector is a VRF drawn by a test key, Randomness is the message signed by the key and Seed is VRFOut.Sum256
+type VRFVector struct {
+	PrivateKey string // filecoin encoding, little endian
+	PublicKey  string
+	Tag        int64
+	RBase      string
+	Height     string
+	Entropy    string
+	Randomness string
+	Proof      string
+	Seed       string
+}
+
+// DeriveVector is a sub-seed derived with VRFOut.Derive
+type DeriveVector struct {
+	Proof string
+	Label string
+	Index string
+	Seed  string
+}
+
+// RngVector is the first outputs of a method of a generator created with trand.NewRng(Seed, Type, Version),
+// or of a game helper or distribution called with the generator and Args. Float outputs are the hex IEEE 754
+// bits, lists are comma separated and the hands of Deck.DealHands, dealt from a fresh trand.StandardDeck()
+// shuffled by NewDeck, are separated by |. Alias.Choose and Zipf.Uint64 build the table from Args once per output.
+type RngVector struct {
+	Type    trand.RNGType
+	Version int
+	Seed    string
+	Method  string
+	Args    []string
+	Outputs []string
+}
+
+// TestKey returns the i-th test key in the filecoin encoding: blake2b-256 of "titan-game-sdk/conformance/key/<i>"
+// with the two top bits of the last byte cleared so the little endian scalar is below the group order
+func TestKey(i int) []byte {
+	key := blake2b.Sum256([]byte(fmt.Sprintf("titan-game-sdk/conformance/key/%d", i)))
+	key[31] &= 0x3f
+
+	return key[:]
+}
+
+// testSeed returns a seed derived from label, the seeds of the generators and the VRF bases
+func testSeed(label string) [32]byte {
+	return blake2b.Sum256([]byte("titan-game-sdk/conformance/seed/" + label))
+}
+
+// Generate builds the suite with outputs outputs per generator method
+func Generate(outputs int) (*Suite, error) {
+	s := &Suite{
+		Version:     SUITE_VERSION,
+		Description: "titan-game-sdk conformance suite",
+		Outputs:     outputs,
+	}
+
+	err := s.addEntropy()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addVRF()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addRng(outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Suite) addEntropy() error {
+	rounds := []*entropy.Round{
+		entropy.NewRound("", "", ""),
+		entropy.NewRound("poker", "round-1", "replay-1", "carol", "alice", "bob"),
+		entropy.NewRound("slots", "42", "replay-42", "alice").
+			AddUint("bet", 100).
+			AddInt("offset", -3).
+			AddBool("bonus", true).
+			AddString("table", "high-roller").
+			AddBytes("nonce", []byte{0xde, 0xad, 0xbe, 0xef}),
+	}
+
+	for _, r := range rounds {
+		encoded, err := r.Encode()
+		if err != nil {
+			return xerrors.Errorf("addEntropy %w", err)
+		}
+
+		v := EntropyVector{
+			GameID:    r.GameID,
+			RoundID:   r.RoundID,
+			ReplayID:  r.ReplayID,
+			PlayerIDs: r.PlayerIDs,
+			Fields:    []EntropyField{},
+			Encoded:   hex.EncodeToString(encoded),
+		}
+		if v.PlayerIDs == nil {
+			v.PlayerIDs = []string{}
+		}
+		for _, f := range r.Extra {
+			v.Fields = append(v.Fields, EntropyField{Name: f.Name, Type: f.Type.String(), Value: hex.EncodeToString(f.Value)})
+		}
+
+		s.Entropy = append(s.Entropy, v)
+	}
+
+	return nil
+}
+
+func (s *Suite) addVRF() error {
+	entropies := [][]byte{nil, []byte("conformance-entropy")}
+	if len(s.Entropy) > 0 {
+		encoded, err := hex.DecodeString(s.Entropy[len(s.Entropy)-1].Encoded)
+		if err != nil {
+			return xerrors.Errorf("addVRF %w", err)
+		}
+		entropies = append(entropies, encoded)
+	}
+
+	tags := []gamevrf.DomainSeparationTag{
+		gamevrf.DomainSeparationTag_GameBasic,
+		gamevrf.DomainSeparationTag_GameRound,
+		gamevrf.DomainSeparationTag_GameDefinedMin + 1,
+	}
+
+	for k := 0; k < 2; k++ {
+		key := TestKey(k)
+		pub, err := gamevrf.FilBlsKey2PublicKey(key)
+		if err != nil {
+			return xerrors.Errorf("addVRF %w", err)
+		}
+
+		for i, tag := range tags {
+			rbase := testSeed(fmt.Sprintf("rbase/%d/%d", k, i))
+			height := uint64(3000000 + 1000*k + i)
+			ent := entropies[i%len(entropies)]
+
+			randomness, err := gamevrf.DrawRandomness(rbase[:], tag, height, ent)
+			if err != nil {
+				return xerrors.Errorf("addVRF %w", err)
+			}
+
+			vrfout, err := gamevrf.GenerateVRF(tag, gamevrf.FilBlsKey2KyberBlsKey(key), rbase[:], height, ent)
+			if err != nil {
+				return xerrors.Errorf("addVRF %w", err)
+			}
+
+			err = gamevrf.VerifyVRF(pub, tag, rbase[:], ent, vrfout)
+			if err != nil {
+				return xerrors.Errorf("addVRF %w", err)
+			}
+
+			seed := vrfout.Sum256()
+			s.VRF = append(s.VRF, VRFVector{
+				PrivateKey: hex.EncodeToString(key),
+				PublicKey:  hex.EncodeToString(pub),
+				Tag:        int64(tag),
+				RBase:      hex.EncodeToString(rbase[:]),
+				Height:     strconv.FormatUint(height, 10),
+				Entropy:    hex.EncodeToString(ent),
+				Randomness: hex.EncodeToString(randomness),
+				Proof:      hex.EncodeToString(vrfout.Proof),
+				Seed:       hex.EncodeToString(seed[:]),
+			})
+
+			for _, label := range []string{"", "loot"} {
+				for _, index := range []uint64{0, 1, math.MaxUint64} {
+					sub := vrfout.Derive(label, index)
+					s.Derive = append(s.Derive, DeriveVector{
+						Proof: hex.EncodeToString(vrfout.Proof),
+						Label: label,
+						Index: strconv.FormatUint(index, 10),
+						Seed:  hex.EncodeToString(sub[:]),
+					})
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// rngMethods are the generator methods, game helpers and distributions of the suite with their arguments,
+// decimal integers or floats
+var rngMethods = []struct {
+	name string
+	args []string
+	call func(r trand.RngV1, args []string) (string, error)
+}{
+	{"Uint64", nil, func(r trand.RngV1, _ []string) (string, error) { return strconv.FormatUint(r.Uint64(), 10), nil }},
+	{"Float64", nil, func(r trand.RngV1, _ []string) (string, error) { return floatBits(r.Float64()), nil }},
+	{"Intn", []string{"6"}, func(r trand.RngV1, a []string) (string, error) { return strconv.Itoa(r.Intn(intArg(a, 0))), nil }},
+	{"Intn", []string{"1000000007"}, func(r trand.RngV1, a []string) (string, error) { return strconv.Itoa(r.Intn(intArg(a, 0))), nil }},
+	{"Uint64n", []string{"4611686018427387907"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.FormatUint(r.Uint64n(uint64(intArg(a, 0))), 10), nil
+	}},
+	{"Int63n", []string{"52"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.FormatInt(r.Int63n(int64(intArg(a, 0))), 10), nil
+	}},
+	{"IntRange", []string{"-10", "10"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.Itoa(r.IntRange(intArg(a, 0), intArg(a, 1))), nil
+	}},
+	{"Shuffle", []string{"10"}, func(r trand.RngV1, a []string) (string, error) {
+		s := seq(intArg(a, 0))
+		trand.Shuffle(r, s)
+		return joinInts(s), nil
+	}},
+	{"Perm", []string{"52"}, func(r trand.RngV1, a []string) (string, error) { return joinInts(trand.Perm(r, intArg(a, 0))), nil }},
+	{"SampleWithoutReplacement", []string{"20", "5"}, func(r trand.RngV1, a []string) (string, error) {
+		sample, err := trand.SampleWithoutReplacement(r, seq(intArg(a, 0)), intArg(a, 1))
+		return joinInts(sample), err
+	}},
+	{"Alias.Choose", []string{"1", "0", "5", "2"}, func(r trand.RngV1, a []string) (string, error) {
+		alias, err := trand.NewAlias(weightArgs(a))
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(alias.Choose(r)), nil
+	}},
+	{"WeightedChoice", []string{"3", "3", "3", "7", "1000000007"}, func(r trand.RngV1, a []string) (string, error) {
+		i, err := trand.WeightedChoice(r, weightArgs(a))
+		return strconv.Itoa(i), err
+	}},
+	{"RollDice", []string{"5", "6"}, func(r trand.RngV1, a []string) (string, error) {
+		rolls, err := trand.RollDice(r, intArg(a, 0), intArg(a, 1))
+		return joinInts(rolls), err
+	}},
+	{"Deck.DealHands", []string{"4", "5"}, func(r trand.RngV1, a []string) (string, error) {
+		hands, err := trand.NewDeck(r, trand.StandardDeck()).DealHands(intArg(a, 0), intArg(a, 1))
+		if err != nil {
+			return "", err
+		}
+
+		var out []string
+		for _, hand := range hands {
+			cards := make([]int, len(hand))
+			for i, c := range hand {
+				cards[i] = int(c)
+			}
+			out = append(out, joinInts(cards))
+		}
+		return strings.Join(out, "|"), nil
+	}},
+	{"NormFloat64", nil, func(r trand.RngV1, _ []string) (string, error) { return floatBits(trand.NormFloat64(r)), nil }},
+	{"ExpFloat64", nil, func(r trand.RngV1, _ []string) (string, error) { return floatBits(trand.ExpFloat64(r)), nil }},
+	{"Poisson", []string{"4"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.Itoa(trand.Poisson(r, floatArg(a, 0))), nil
+	}},
+	{"Poisson", []string{"250.5"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.Itoa(trand.Poisson(r, floatArg(a, 0))), nil
+	}},
+	{"Binomial", []string{"20", "0.3"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.Itoa(trand.Binomial(r, intArg(a, 0), floatArg(a, 1))), nil
+	}},
+	{"Binomial", []string{"1000", "0.4"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.Itoa(trand.Binomial(r, intArg(a, 0), floatArg(a, 1))), nil
+	}},
+	{"Binomial", []string{"5000", "0.9"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.Itoa(trand.Binomial(r, intArg(a, 0), floatArg(a, 1))), nil
+	}},
+	{"Geometric", []string{"0.05"}, func(r trand.RngV1, a []string) (string, error) {
+		return strconv.FormatInt(trand.Geometric(r, floatArg(a, 0)), 10), nil
+	}},
+	{"Zipf.Uint64", []string{"1.2", "2", "1000"}, func(r trand.RngV1, a []string) (string, error) {
+		z, err := trand.NewZipf(floatArg(a, 0), floatArg(a, 1), uint64(intArg(a, 2)))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(z.Uint64(r), 10), nil
+	}},
+}
+
+// intArg parses the i-th integer argument of a method of rngMethods
+func intArg(args []string, i int) int {
+	v, err := strconv.ParseInt(args[i], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("conformance: invalid integer argument %q", args[i]))
+	}
+
+	return int(v)
+}
+
+// floatArg parses the i-th float argument of a method of rngMethods
+func floatArg(args []string, i int) float64 {
+	v, err := strconv.ParseFloat(args[i], 64)
+	if err != nil {
+		panic(fmt.Sprintf("conformance: invalid float argument %q", args[i]))
+	}
+
+	return v
+}
+
+// weightArgs parses every argument as a weight
+func weightArgs(args []string) []uint64 {
+	weights := make([]uint64, len(args))
+	for i := range args {
+		weights[i] = uint64(intArg(args, i))
+	}
+
+	return weights
+}
+
+// seq returns 0, 1, ..., n-1
+func seq(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+
+	return s
+}
+
+// joinInts returns the comma separated decimal integers
+func joinInts(s []int) string {
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[i] = strconv.Itoa(v)
+	}
+
+	return strings.Join(out, ",")
+}
+
+// floatBits returns the hex IEEE 754 bits of f
+func floatBits(f float64) string {
+	return fmt.Sprintf("%016x", math.Float64bits(f))
+}
+
+func (s *Suite) addRng(outputs int) error {
+	types := []trand.RNGType{
+		trand.RNGType_Normal,
+		trand.RNGType_Cipher,
+		trand.RNGType_PCG64DXSM,
+		trand.RNGType_ChaCha20,
+		trand.RNGType_CtrDRBG,
+	}
+	versions := []trand.AlgorithmVersion{trand.AlgorithmVersion_Legacy, trand.AlgorithmVersion_V1}
+
+	for _, typ := range types {
+		seed := testSeed("rng/" + typ)
+		for _, version := range versions {
+			for _, m := range rngMethods {
+				r := trand.NewRng(seed, typ, version)
+				v := RngVector{
+					Type:    typ,
+					Version: int(version),
+					Seed:    hex.EncodeToString(seed[:]),
+					Method:  m.name,
+					Args:    append([]string{}, m.args...),
+				}
+				for i := 0; i < outputs; i++ {
+					out, err := m.call(r, m.args)
+					if err != nil {
+						return xerrors.Errorf("addRng %s %s: %w", typ, m.name, err)
+					}
+					v.Outputs = append(v.Outputs, out)
+				}
+
+				s.Rng = append(s.Rng, v)
+			}
+		}
+	}
+
+	return nil
+}
@@ -4,40 +4,77 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/xerrors"
 )
 
+const (
+	// maxIdleConnsPerHost keeps connections to the node open for concurrent requests,
+	// http.DefaultTransport keeps only 2
+	maxIdleConnsPerHost = 16
+	// maxErrorBody is the size of the body kept in an HTTPError
+	maxErrorBody = 512
+)
+
 type request struct {
 	Jsonrpc string        `json:"jsonrpc"`
 	Method  string        `json:"method"`
 	Params  []interface{} `json:"params"`
-	ID      int           `json:"id"`
+	ID      uint64        `json:"id"`
 }
 
 // Response defines a JSON RPC response from the spec
 // http://www.jsonrpc.org/specification#response_object
 type response struct {
-	Jsonrpc string      `json:"jsonrpc"`
-	Result  interface{} `json:"result,omitempty"`
-	ID      interface{} `json:"id"`
-	Error   *respError  `json:"error,omitempty"`
+	Jsonrpc string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	ID      json.RawMessage `json:"id"`
+	Error   *RPCError       `json:"error,omitempty"`
 }
 
-type respError struct {
-	Code    errorCode       `json:"code"`
+// RPCError is the error object of a JSON RPC response, returned when the node fails a call
+type RPCError struct {
+	Code    int             `json:"code"`
 	Message string          `json:"message"`
 	Meta    json.RawMessage `json:"meta,omitempty"`
 }
 
-type errorCode int
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// HTTPError is returned when the node answers with a status other than 200,
+// after the retries for 5xx and 429 responses are exhausted
+type HTTPError struct {
+	StatusCode int
+	Body       string // start of the body, lotus explains authorization failures there
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the node may answer the request later, for 5xx and 429 responses
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
 type params []interface{}
 
 type Client struct {
-	cfg Config
+	cfg    Config
+	client *http.Client
+	nextID uint64
 }
 
 func New(opts ...Option) *Client {
@@ -48,76 +85,183 @@ func New(opts ...Option) *Client {
 	}
 
 	return &Client{
-		cfg: cfg,
+		cfg:    cfg,
+		client: newHTTPClient(cfg),
 	}
 }
 
-func requestLotus(url string, data request) (*response, error) {
+// newHTTPClient returns the configured http Client, or a client of the configured transport,
+// or a client of its own transport so the connections to the node are reused
+func newHTTPClient(cfg Config) *http.Client {
+	if cfg.HTTPClient != nil {
+		return cfg.HTTPClient
+	}
+
+	if cfg.Transport != nil {
+		return &http.Client{Transport: cfg.Transport}
+	}
+
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t = t.Clone()
+		t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		return &http.Client{Transport: t}
+	}
+
+	return http.DefaultClient
+}
+
+// requestLotus sends a json rpc request to the lotus node, the request is bounded by ctx, every attempt
+// by the configured timeout. 5xx and 429 responses are retried with an exponential backoff.
+func (c *Client) requestLotus(ctx context.Context, data request) (*response, error) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
-	if err != nil {
-		return nil, err
+	for attempt := 0; ; attempt++ {
+		rsp, retryAfter, err := c.send(ctx, jsonData)
+		if err == nil {
+			return c.decode(rsp, data.ID)
+		}
+
+		var httpErr *HTTPError
+		if !xerrors.As(err, &httpErr) || !httpErr.Temporary() || attempt >= c.cfg.Retries {
+			return nil, err
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, xerrors.Errorf("%s: %w", err, ctx.Err())
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt+1, RetryBackoff doubled at every attempt up to MaxRetryBackoff
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.RetryBackoff
+	for i := 0; i < attempt && delay < c.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
-	defer cancel()
+	if c.cfg.MaxRetryBackoff > 0 && delay > c.cfg.MaxRetryBackoff {
+		delay = c.cfg.MaxRetryBackoff
+	}
 
-	req = req.WithContext(ctx)
+	return delay
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// send posts a request body once, it returns the response body of a 200 response,
+// or an HTTPError and the delay asked by a Retry-After header
+func (c *Client) send(ctx context.Context, body []byte) ([]byte, time.Duration, error) {
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.NodeURL, bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// the body is read to the end so the connection is reused
+	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if len(b) > maxErrorBody {
+			b = b[:maxErrorBody]
+		}
+		return nil, retryAfter(resp.Header.Get("Retry-After")), &HTTPError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
+	}
+
+	return b, 0, nil
+}
+
+// retryAfter parses a Retry-After header in seconds, HTTP dates are ignored
+func retryAfter(v string) time.Duration {
+	seconds, err := strconv.Atoi(v)
+	if err != nil || seconds < 0 {
+		return 0
 	}
 
+	return time.Duration(seconds) * time.Second
+}
+
+// decode decodes a response body and checks it answers the request id
+func (c *Client) decode(body []byte, id uint64) (*response, error) {
 	var rsp response
-	err = json.Unmarshal(body, &rsp)
+	err := json.Unmarshal(body, &rsp)
 	if err != nil {
 		return nil, err
 	}
 
 	if rsp.Error != nil {
-		return nil, xerrors.New(rsp.Error.Message)
+		return nil, rsp.Error
+	}
+
+	if string(rsp.ID) != fmtID(id) {
+		return nil, xerrors.Errorf("response id %s doesn't match request id %d", rsp.ID, id)
 	}
 
 	return &rsp, nil
 }
 
-// ChainGetTipSetByHeight lotus ChainGetTipSetByHeight api
-func (c *Client) ChainGetTipSetByHeight(height int64) (*TipSet, error) {
-	serializedParams := params{
-		height, nil,
-	}
+// fmtID returns the JSON encoding of a request id
+func fmtID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
 
+// callLotus calls a lotus api method and decodes its result into out
+func (c *Client) callLotus(ctx context.Context, method string, serializedParams params, out interface{}) error {
 	req := request{
 		Jsonrpc: "2.0",
-		Method:  "Filecoin.ChainGetTipSetByHeight",
+		Method:  method,
 		Params:  serializedParams,
-		ID:      1,
+		ID:      atomic.AddUint64(&c.nextID, 1),
 	}
 
-	rsp, err := requestLotus(c.cfg.NodeURL, req)
+	rsp, err := c.requestLotus(ctx, req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	b, err := json.Marshal(rsp.Result)
-	if err != nil {
-		return nil, err
+	if len(rsp.Result) == 0 {
+		return json.Unmarshal([]byte("null"), out)
 	}
 
+	return json.Unmarshal(rsp.Result, out)
+}
+
+// ChainGetTipSetByHeight lotus ChainGetTipSetByHeight api
+func (c *Client) ChainGetTipSetByHeight(height int64) (*TipSet, error) {
+	return c.ChainGetTipSetByHeightContext(context.Background(), height)
+}
+
+// ChainGetTipSetByHeightContext lotus ChainGetTipSetByHeight api, the request is canceled with ctx
+func (c *Client) ChainGetTipSetByHeightContext(ctx context.Context, height int64) (*TipSet, error) {
 	var ts TipSet
-	err = json.Unmarshal(b, &ts)
+	err := c.callLotus(ctx, "Filecoin.ChainGetTipSetByHeight", params{height, nil}, &ts)
 	if err != nil {
 		return nil, err
 	}
@@ -127,25 +271,13 @@ func (c *Client) ChainGetTipSetByHeight(height int64) (*TipSet, error) {
 
 // ChainHead lotus ChainHead api
 func (c *Client) ChainHead() (*TipSet, error) {
-	req := request{
-		Jsonrpc: "2.0",
-		Method:  "Filecoin.ChainHead",
-		Params:  nil,
-		ID:      1,
-	}
-
-	rsp, err := requestLotus(c.cfg.NodeURL, req)
-	if err != nil {
-		return nil, err
-	}
-
-	b, err := json.Marshal(rsp.Result)
-	if err != nil {
-		return nil, err
-	}
+	return c.ChainHeadContext(context.Background())
+}
 
+// ChainHeadContext lotus ChainHead api, the request is canceled with ctx
+func (c *Client) ChainHeadContext(ctx context.Context) (*TipSet, error) {
 	var ts TipSet
-	err = json.Unmarshal(b, &ts)
+	err := c.callLotus(ctx, "Filecoin.ChainHead", nil, &ts)
 	if err != nil {
 		return nil, err
 	}
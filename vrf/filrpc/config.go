@@ -1,12 +1,20 @@
 package filrpc
 
 import (
+	"net/http"
 	"time"
 )
 
 type Config struct {
 	NodeURL           string
+	WebsocketURL      string
+	Token             string
 	Timeout           time.Duration
+	HTTPClient        *http.Client
+	Transport         http.RoundTripper
+	Retries           int
+	RetryBackoff      time.Duration
+	MaxRetryBackoff   time.Duration
 	ContractorAddress string
 	PrivateKeyStr     string
 }
@@ -17,7 +25,10 @@ type Option func(opts *Config)
 // DefaultOption returns a default set of options.
 func DefaultOption() Config {
 	return Config{
-		Timeout: 30 * time.Second,
+		Timeout:         30 * time.Second,
+		Retries:         2,
+		RetryBackoff:    250 * time.Millisecond,
+		MaxRetryBackoff: 5 * time.Second,
 	}
 }
 
@@ -28,13 +39,53 @@ func NodeURLOption(address string) Option {
 	}
 }
 
-// TimeoutOption specifies a time limit for requests made by the http Client.
+// WebsocketURLOption set the websocket url of ChainNotify, derived from the node url if not set
+func WebsocketURLOption(address string) Option {
+	return func(opts *Config) {
+		opts.WebsocketURL = address
+	}
+}
+
+// TokenOption sends token as a bearer token with every request, the JWT of a private lotus node
+func TokenOption(token string) Option {
+	return func(opts *Config) {
+		opts.Token = token
+	}
+}
+
+// TimeoutOption specifies a time limit for every attempt of a request made by the http Client.
 func TimeoutOption(timeout time.Duration) Option {
 	return func(opts *Config) {
 		opts.Timeout = timeout
 	}
 }
 
+// HTTPClientOption specifies the http Client used to talk to the lotus node,
+// a client reusing its connections to the node is used if not set
+func HTTPClientOption(client *http.Client) Option {
+	return func(opts *Config) {
+		opts.HTTPClient = client
+	}
+}
+
+// TransportOption specifies the transport of the http Client, ignored when HTTPClientOption is set
+func TransportOption(transport http.RoundTripper) Option {
+	return func(opts *Config) {
+		opts.Transport = transport
+	}
+}
+
+// RetryOption retries requests answered with a 5xx or 429 status up to retries times, waiting backoff
+// before the first retry and doubling it up to maxBackoff, or longer when the node sends Retry-After.
+// A zero maxBackoff keeps the backoff constant, zero retries disables them.
+func RetryOption(retries int, backoff, maxBackoff time.Duration) Option {
+	return func(opts *Config) {
+		opts.Retries = retries
+		opts.RetryBackoff = backoff
+		opts.MaxRetryBackoff = maxBackoff
+	}
+}
+
 // ContractorAddressOption specifies a contractor address
 func ContractorAddressOption(id string) Option {
 	return func(opts *Config) {
@@ -0,0 +1,226 @@
+package filrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/xerrors"
+)
+
+// Types of the head changes notified by ChainNotify
+const (
+	HCRevert  = "revert"
+	HCApply   = "apply"
+	HCCurrent = "current"
+)
+
+const (
+	// notifyPingPeriod is the interval of the pings keeping the subscription alive
+	notifyPingPeriod = 30 * time.Second
+	// notifyPongWait is how long the subscription waits for a message or a pong before it's considered dead
+	notifyPongWait = 2 * notifyPingPeriod
+	// notifyWriteWait is the time limit of a write to the websocket
+	notifyWriteWait = 10 * time.Second
+	// notifyBuffer is the number of head changes buffered for a slow reader
+	notifyBuffer = 16
+)
+
+// HeadChange is a change of the chain head, the first notification of a subscription is
+// the current head, then every reverted tipset comes before the applied ones
+type HeadChange struct {
+	Type string
+	Val  *TipSet
+}
+
+// message is a JSON RPC message of the websocket, either the response to the subscription
+// or a channel notification xrpc.ch.val / xrpc.ch.close of the node
+type message struct {
+	Jsonrpc string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id,omitempty"`
+	Method  string            `json:"method,omitempty"`
+	Params  []json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage   `json:"result,omitempty"`
+	Error   *RPCError         `json:"error,omitempty"`
+}
+
+// websocketURL returns the configured websocket url or the node url with a ws or wss scheme
+func (c *Client) websocketURL() (string, error) {
+	if c.cfg.WebsocketURL != "" {
+		return c.cfg.WebsocketURL, nil
+	}
+
+	u, err := url.Parse(c.cfg.NodeURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", xerrors.Errorf("no websocket url for node url %s", c.cfg.NodeURL)
+	}
+
+	return u.String(), nil
+}
+
+// ChainNotify lotus ChainNotify api over a websocket, the changes of the chain head are sent on the returned channel
+// until ctx is canceled or the connection is lost, then the channel is closed. The subscription is bounded by the
+// configured timeout, not its notifications.
+func (c *Client) ChainNotify(ctx context.Context) (<-chan []HeadChange, error) {
+	u, err := c.websocketURL()
+	if err != nil {
+		return nil, xerrors.Errorf("ChainNotify %w", err)
+	}
+
+	header := http.Header{}
+	if c.cfg.Token != "" {
+		header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: c.cfg.Timeout,
+	}
+	conn, rsp, err := dialer.DialContext(ctx, u, header)
+	if err != nil {
+		if rsp != nil {
+			return nil, xerrors.Errorf("ChainNotify %w", &HTTPError{StatusCode: rsp.StatusCode})
+		}
+		return nil, xerrors.Errorf("ChainNotify %w", err)
+	}
+
+	ch, err := c.subscribe(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, xerrors.Errorf("ChainNotify %w", err)
+	}
+
+	out := make(chan []HeadChange, notifyBuffer)
+	go readNotify(ctx, conn, ch, out)
+
+	return out, nil
+}
+
+// subscribe calls ChainNotify and returns the id of the channel of the notifications
+func (c *Client) subscribe(ctx context.Context, conn *websocket.Conn) (string, error) {
+	deadline := time.Now().Add(notifyPongWait)
+	if c.cfg.Timeout > 0 {
+		deadline = time.Now().Add(c.cfg.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	req := request{
+		Jsonrpc: "2.0",
+		Method:  "Filecoin.ChainNotify",
+		Params:  params{},
+		ID:      atomic.AddUint64(&c.nextID, 1),
+	}
+
+	conn.SetWriteDeadline(deadline)
+	err := conn.WriteJSON(req)
+	if err != nil {
+		return "", err
+	}
+
+	conn.SetReadDeadline(deadline)
+	var msg message
+	err = conn.ReadJSON(&msg)
+	if err != nil {
+		return "", err
+	}
+
+	if msg.Error != nil {
+		return "", msg.Error
+	}
+
+	if string(msg.ID) != fmtID(req.ID) {
+		return "", xerrors.Errorf("response id %s doesn't match request id %d", msg.ID, req.ID)
+	}
+
+	if len(msg.Result) == 0 {
+		return "", xerrors.New("no channel id in the response")
+	}
+
+	return string(msg.Result), nil
+}
+
+// readNotify forwards the notifications of channel ch to out until ctx is done or the connection is lost
+func readNotify(ctx context.Context, conn *websocket.Conn, ch string, out chan<- []HeadChange) {
+	done := make(chan struct{})
+	defer close(out)
+	defer close(done)
+	defer conn.Close()
+
+	// pings detect a dead connection, closing it on ctx unblocks the reads
+	go func() {
+		ticker := time.NewTicker(notifyPingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(notifyWriteWait))
+				conn.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(notifyWriteWait))
+				if err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(notifyPongWait))
+	})
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(notifyPongWait))
+
+		var msg message
+		err := conn.ReadJSON(&msg)
+		if err != nil {
+			return
+		}
+
+		if len(msg.Params) == 0 || string(msg.Params[0]) != ch {
+			continue
+		}
+
+		switch msg.Method {
+		case "xrpc.ch.val":
+			if len(msg.Params) < 2 {
+				return
+			}
+
+			var changes []HeadChange
+			err = json.Unmarshal(msg.Params[1], &changes)
+			if err != nil {
+				return
+			}
+
+			select {
+			case out <- changes:
+			case <-ctx.Done():
+				return
+			}
+		case "xrpc.ch.close":
+			return
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package filrpc
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+)
+
+// MinerInfo is the subset of the lotus MinerInfo needed to check block tickets
+type MinerInfo struct {
+	Owner  address.Address
+	Worker address.Address // ID address of the worker key
+}
+
+// StateMinerInfoContext lotus StateMinerInfo api, tsk is the tipset key to read the state at, nil for the chain head
+func (c *Client) StateMinerInfoContext(ctx context.Context, miner address.Address, tsk []cid.Cid) (*MinerInfo, error) {
+	var info MinerInfo
+	err := c.callLotus(ctx, "Filecoin.StateMinerInfo", params{miner, tsk}, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// StateAccountKeyContext lotus StateAccountKey api, resolves an ID address to its public key address
+func (c *Client) StateAccountKeyContext(ctx context.Context, addr address.Address, tsk []cid.Cid) (address.Address, error) {
+	var key address.Address
+	err := c.callLotus(ctx, "Filecoin.StateAccountKey", params{addr, tsk}, &key)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	return key, nil
+}
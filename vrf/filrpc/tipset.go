@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"sort"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/ipfs/go-cid"
 	"github.com/minio/blake2b-simd"
 	"golang.org/x/xerrors"
@@ -29,9 +30,18 @@ type Ticket struct {
 	VRFProof []byte
 }
 
+// BeaconEntry is a drand beacon entry included in a block
+type BeaconEntry struct {
+	Round uint64
+	Data  []byte
+}
+
 // BlockHeader represents the header of a block in the blockchain
 type BlockHeader struct {
-	Ticket *Ticket // 1 unique per block/miner: should be a valid VRF
+	Miner         address.Address // 0 unique per block/miner
+	Ticket        *Ticket         // 1 unique per block/miner: should be a valid VRF
+	BeaconEntries []BeaconEntry   // 3 identical for all blocks in same tipset
+	Parents       []cid.Cid       // 5 identical for all blocks in same tipset
 	//ParentWeight          BigInt            // 6 identical for all blocks in same tipset
 	Height uint64 // 7 identical for all blocks in same tipset
 }
@@ -64,6 +74,9 @@ func (ts *TipSet) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	// block cids can't be computed from the partial headers, keep the ones reported by the node
+	ots.cids = ets.Cids
+
 	*ts = *ots
 
 	return nil
@@ -99,6 +112,37 @@ func NewTipSet(blks []*BlockHeader) (*TipSet, error) {
 	return &ts, nil
 }
 
+// NewTipSetWithCids creates a new TipSet from the given blocks and their cids, cids[i] is the cid of blks[i]
+func NewTipSetWithCids(blks []*BlockHeader, cids []cid.Cid) (*TipSet, error) {
+	if len(blks) != len(cids) {
+		return nil, xerrors.Errorf("NewTipSetWithCids %d blocks but %d cids", len(blks), len(cids))
+	}
+
+	idx := make([]int, len(blks))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return blks[idx[i]].LastTicket().Less(blks[idx[j]].LastTicket())
+	})
+
+	sortedBlks := make([]*BlockHeader, len(blks))
+	sortedCids := make([]cid.Cid, len(cids))
+	for i, j := range idx {
+		sortedBlks[i] = blks[j]
+		sortedCids[i] = cids[j]
+	}
+
+	ts, err := NewTipSet(sortedBlks)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.cids = sortedCids
+
+	return ts, nil
+}
+
 // MinTicket returns the minimum VRF proof in the TipSet
 func (ts *TipSet) MinTicket() *Ticket {
 	return ts.MinTicketBlock().Ticket
@@ -124,6 +168,11 @@ func (ts *TipSet) Blocks() []*BlockHeader {
 	return ts.blks
 }
 
+// Cids returns the cids of the blocks in the TipSet, it is empty if the TipSet wasn't decoded from a lotus node
+func (ts *TipSet) Cids() []cid.Cid {
+	return ts.cids
+}
+
 // Height returns the height of the TipSet
 func (ts *TipSet) Height() uint64 {
 	return ts.height
@@ -0,0 +1,180 @@
+package gamevrf
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"golang.org/x/xerrors"
+)
+
+// batchScalarBits is the size of the random scalars used to combine the items of a batch,
+// an invalid batch passes the aggregate check with probability 2^-batchScalarBits
+const batchScalarBits = 128
+
+// VRFBatchItem is a single VRF to verify with VerifyVRFBatch, fields match the arguments of VerifyVRF
+type VRFBatchItem struct {
+	PublicKey []byte
+	Pers      DomainSeparationTag
+	RBase     []byte
+	Entropy   []byte
+	VRF       *VRFOut
+}
+
+// VRFBatchError reports the items of a batch that failed verification
+type VRFBatchError struct {
+	Indexes []int   // indexes of the invalid items in the batch
+	Errs    []error // verification error of each invalid item
+}
+
+// Error implements error
+func (e *VRFBatchError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("VerifyVRFBatch %d invalid item(s):", len(e.Indexes)))
+	for i, idx := range e.Indexes {
+		sb.WriteString(fmt.Sprintf(" [%d] %s;", idx, e.Errs[i]))
+	}
+
+	return sb.String()
+}
+
+// batchEntry holds the decoded points of a batch item
+type batchEntry struct {
+	index  int
+	pubKey *bls12381.PointG1
+	msg    *bls12381.PointG2
+	sig    *bls12381.PointG2
+}
+
+// VerifyVRFBatch verifies many VRF proofs with a single randomized aggregate pairing check.
+// Each item i gets a random scalar r_i and the batch is accepted if
+//
+//	e(g1, sum(r_i * proof_i)) == prod(e(pubkey, sum(r_i * H(m_i)))) over distinct pubkeys
+//
+// If the aggregate check fails every item is verified on its own and a *VRFBatchError lists the invalid ones.
+func VerifyVRFBatch(items []VRFBatchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+
+	batchErr := &VRFBatchError{}
+	entries := make([]batchEntry, 0, len(items))
+	for i, item := range items {
+		entry, err := decodeBatchItem(g1, g2, i, item)
+		if err != nil {
+			batchErr.Indexes = append(batchErr.Indexes, i)
+			batchErr.Errs = append(batchErr.Errs, err)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	ok, err := aggregateCheck(g1, g2, items, entries)
+	if err != nil {
+		return xerrors.Errorf("VerifyVRFBatch aggregate check failed: %w", err)
+	}
+
+	if !ok {
+		// find the bad items one by one
+		for _, entry := range entries {
+			item := items[entry.index]
+			err := VerifyVRF(item.PublicKey, item.Pers, item.RBase, item.Entropy, item.VRF)
+			if err != nil {
+				batchErr.Indexes = append(batchErr.Indexes, entry.index)
+				batchErr.Errs = append(batchErr.Errs, err)
+			}
+		}
+	}
+
+	if len(batchErr.Indexes) > 0 {
+		return batchErr
+	}
+
+	return nil
+}
+
+// decodeBatchItem decodes the public key and proof of an item and hashes its randomness to G2
+func decodeBatchItem(g1 *bls12381.G1, g2 *bls12381.G2, index int, item VRFBatchItem) (batchEntry, error) {
+	if item.VRF == nil {
+		return batchEntry{}, xerrors.Errorf("VerifyVRFBatch item has no VRFOut")
+	}
+
+	pubKey, err := g1.FromCompressed(item.PublicKey)
+	if err != nil {
+		return batchEntry{}, xerrors.Errorf("VerifyVRFBatch decode public key failed: %w", err)
+	}
+
+	sig, err := g2.FromCompressed(item.VRF.Proof)
+	if err != nil {
+		return batchEntry{}, xerrors.Errorf("VerifyVRFBatch decode proof failed: %w", err)
+	}
+
+	randomness, err := drawRandomness(item.RBase, item.Pers, item.VRF.Height, item.Entropy)
+	if err != nil {
+		return batchEntry{}, xerrors.Errorf("VerifyVRFBatch drawRandomness failed: %w", err)
+	}
+
+	msg, err := g2.HashToCurve(randomness, []byte(DST))
+	if err != nil {
+		return batchEntry{}, xerrors.Errorf("VerifyVRFBatch hash to curve failed: %w", err)
+	}
+
+	return batchEntry{
+		index:  index,
+		pubKey: pubKey,
+		msg:    msg,
+		sig:    sig,
+	}, nil
+}
+
+// aggregateCheck runs the randomized pairing check over entries, messages signed by the same key share one pairing
+func aggregateCheck(g1 *bls12381.G1, g2 *bls12381.G2, items []VRFBatchItem, entries []batchEntry) (bool, error) {
+	if len(entries) == 0 {
+		return true, nil
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), batchScalarBits)
+
+	type keyGroup struct {
+		pubKey *bls12381.PointG1
+		msgAcc *bls12381.PointG2
+	}
+
+	groups := make(map[string]*keyGroup)
+	order := make([]string, 0)
+	sigAcc := g2.Zero()
+	for _, entry := range entries {
+		r, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return false, err
+		}
+		// a zero scalar would drop the item from the check
+		r.Add(r, big.NewInt(1))
+
+		key := string(items[entry.index].PublicKey)
+		group, ok := groups[key]
+		if !ok {
+			group = &keyGroup{pubKey: entry.pubKey, msgAcc: g2.Zero()}
+			groups[key] = group
+			order = append(order, key)
+		}
+
+		g2.Add(group.msgAcc, group.msgAcc, g2.MulScalarBig(g2.New(), entry.msg, r))
+		g2.Add(sigAcc, sigAcc, g2.MulScalarBig(g2.New(), entry.sig, r))
+	}
+
+	engine := bls12381.NewEngine()
+	for _, key := range order {
+		group := groups[key]
+		engine.AddPair(group.pubKey, group.msgAcc)
+	}
+	engine.AddPairInv(g1.One(), sigAcc)
+
+	return engine.Check(), nil
+}
@@ -13,6 +13,9 @@ import (
 // Mark: filecoin blst rust implementation use follow DST
 const DST = string("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_")
 
+// KTBLS is the lotus KeyInfo type of BLS keys, the only type usable for VRFs
+const KTBLS = "bls"
+
 // KeyInfo represents information about a key, including its type and private key
 type KeyInfo struct {
 	Type       string
@@ -86,6 +89,10 @@ func FilBlsKeyFromString(privateKey string) ([]byte, error) {
 		return nil, err
 	}
 
+	if keyInfo.Type != KTBLS {
+		return nil, xerrors.Errorf("FilBlsKeyFromString unsupported key type %q, expect %q", keyInfo.Type, KTBLS)
+	}
+
 	return keyInfo.PrivateKey, nil
 }
 
@@ -0,0 +1,269 @@
+package gamevrf
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+)
+
+// CACHE_UNFINALIZED_TTL is how long a tipset within FILECOIN_CHAIN_FINALITY of the head is cached, it may be reorged
+const CACHE_UNFINALIZED_TTL = FILECOIN_EPOCH_DURATION * time.Second
+
+// TipSetStore persists finalized tipsets so they never need to be fetched again
+type TipSetStore interface {
+	// Get returns the tipset stored for the requested height, ok is false if there is none
+	Get(height int64) (ts *filrpc.TipSet, ok bool, err error)
+	// Put stores the tipset returned for the requested height
+	Put(height int64, ts *filrpc.TipSet) error
+}
+
+// CacheStats reports the activity of a CachedProvider
+type CacheStats struct {
+	Hits      uint64 // requests served from memory
+	StoreHits uint64 // requests served from the TipSetStore
+	Shared    uint64 // requests sharing an in-flight fetch of another caller
+	Misses    uint64 // requests fetched from the underlying provider
+}
+
+// HitRate returns the ratio of requests not fetched from the underlying provider
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.StoreHits + s.Shared + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(total-s.Misses) / float64(total)
+}
+
+// cacheEntry is an element of the lru list
+type cacheEntry struct {
+	height  int64
+	ts      *filrpc.TipSet
+	expires time.Time // zero for finalized heights
+}
+
+// inflightCall is a fetch shared by concurrent callers of the same height, it runs until it completes
+// or every caller has left
+type inflightCall struct {
+	done    chan struct{}
+	ts      *filrpc.TipSet
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// CachedProvider is a TipSetProvider keeping the tipsets of the last requested heights in a bounded lru cache,
+// concurrent requests of the same height share a single fetch and finalized tipsets are written to an optional store.
+// Heights within FILECOIN_CHAIN_FINALITY of the head are only cached for CACHE_UNFINALIZED_TTL.
+type CachedProvider struct {
+	provider TipSetProvider
+	store    TipSetStore
+	size     int
+
+	lck      sync.Mutex
+	lru      *list.List
+	entries  map[int64]*list.Element
+	inflight map[int64]*inflightCall
+	head     uint64 // highest chain head seen, used to tell finalized heights
+
+	hits      uint64
+	storeHits uint64
+	shared    uint64
+	misses    uint64
+}
+
+// NewCachedProvider wraps provider with a cache of size heights, store may be nil
+func NewCachedProvider(provider TipSetProvider, size int, store TipSetStore) *CachedProvider {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &CachedProvider{
+		provider: provider,
+		store:    store,
+		size:     size,
+		lru:      list.New(),
+		entries:  make(map[int64]*list.Element),
+		inflight: make(map[int64]*inflightCall),
+	}
+}
+
+// Stats returns the cache statistics
+func (p *CachedProvider) Stats() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadUint64(&p.hits),
+		StoreHits: atomic.LoadUint64(&p.storeHits),
+		Shared:    atomic.LoadUint64(&p.shared),
+		Misses:    atomic.LoadUint64(&p.misses),
+	}
+}
+
+// ChainHead implements TipSetProvider, the head is never cached but is remembered to tell finalized heights
+func (p *CachedProvider) ChainHead(ctx context.Context) (*filrpc.TipSet, error) {
+	ts, err := p.provider.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lck.Lock()
+	if ts.Height() > p.head {
+		p.head = ts.Height()
+	}
+	p.lck.Unlock()
+
+	return ts, nil
+}
+
+// ChainGetTipSetByHeight implements TipSetProvider
+func (p *CachedProvider) ChainGetTipSetByHeight(ctx context.Context, height int64) (*filrpc.TipSet, error) {
+	p.lck.Lock()
+	if elem, ok := p.entries[height]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			p.lru.MoveToFront(elem)
+			p.lck.Unlock()
+
+			atomic.AddUint64(&p.hits, 1)
+			return entry.ts, nil
+		}
+
+		p.lru.Remove(elem)
+		delete(p.entries, height)
+	}
+
+	call, ok := p.inflight[height]
+	if ok {
+		atomic.AddUint64(&p.shared, 1)
+	} else {
+		// the fetch isn't bound to the ctx of the first caller, it is canceled when every caller has left
+		var fctx context.Context
+		call = &inflightCall{done: make(chan struct{})}
+		fctx, call.cancel = context.WithCancel(context.Background())
+		p.inflight[height] = call
+
+		go p.fetchShared(fctx, height, call)
+	}
+	call.waiters++
+	p.lck.Unlock()
+
+	select {
+	case <-call.done:
+		return call.ts, call.err
+	case <-ctx.Done():
+		p.leave(height, call)
+		return nil, ctx.Err()
+	}
+}
+
+// fetchShared runs the fetch of call and caches its result
+func (p *CachedProvider) fetchShared(ctx context.Context, height int64, call *inflightCall) {
+	defer call.cancel()
+
+	call.ts, call.err = p.fetch(ctx, height)
+
+	p.lck.Lock()
+	if p.inflight[height] == call {
+		delete(p.inflight, height)
+	}
+	if call.err == nil {
+		p.add(height, call.ts)
+	}
+	p.lck.Unlock()
+
+	close(call.done)
+}
+
+// leave removes a caller whose ctx is done from call, the last one cancels the fetch
+func (p *CachedProvider) leave(height int64, call *inflightCall) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+
+	// callers arriving from now on start a new fetch instead of sharing the canceled one
+	if p.inflight[height] == call {
+		delete(p.inflight, height)
+	}
+	call.cancel()
+}
+
+// fetch reads the tipset from the store or the underlying provider, writing it to the store once it is final
+func (p *CachedProvider) fetch(ctx context.Context, height int64) (*filrpc.TipSet, error) {
+	if p.store != nil {
+		ts, ok, err := p.store.Get(height)
+		if err == nil && ok {
+			atomic.AddUint64(&p.storeHits, 1)
+			return ts, nil
+		}
+	}
+
+	atomic.AddUint64(&p.misses, 1)
+	ts, err := p.provider.ChainGetTipSetByHeight(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.headHeight() == 0 {
+		// learn the head once so finalized heights can be told even if ChainHead is never called
+		_, _ = p.ChainHead(ctx)
+	}
+
+	if p.store != nil && p.isFinal(height) {
+		// the store is only an optimization, a failed write is fetched again next time
+		_ = p.store.Put(height, ts)
+	}
+
+	return ts, nil
+}
+
+// headHeight returns the highest head seen
+func (p *CachedProvider) headHeight() uint64 {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	return p.head
+}
+
+// isFinal reports whether height is at least FILECOIN_CHAIN_FINALITY epochs below the highest head seen
+func (p *CachedProvider) isFinal(height int64) bool {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	return p.final(height)
+}
+
+// final is isFinal for a caller holding p.lck
+func (p *CachedProvider) final(height int64) bool {
+	return p.head >= FILECOIN_CHAIN_FINALITY && height >= 0 && uint64(height) <= p.head-FILECOIN_CHAIN_FINALITY
+}
+
+// add puts a tipset in the lru cache evicting the least recently used height, the caller holds p.lck.
+// Heights that aren't final expire after CACHE_UNFINALIZED_TTL.
+func (p *CachedProvider) add(height int64, ts *filrpc.TipSet) {
+	var expires time.Time
+	if !p.final(height) {
+		expires = time.Now().Add(CACHE_UNFINALIZED_TTL)
+	}
+
+	if elem, ok := p.entries[height]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.ts = ts
+		entry.expires = expires
+		p.lru.MoveToFront(elem)
+		return
+	}
+
+	p.entries[height] = p.lru.PushFront(&cacheEntry{height: height, ts: ts, expires: expires})
+	for p.lru.Len() > p.size {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(*cacheEntry).height)
+	}
+}
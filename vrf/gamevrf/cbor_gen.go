@@ -0,0 +1,532 @@
+// Code generated by github.com/whyrusleeping/cbor-gen. DO NOT EDIT.
+
+package gamevrf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	cid "github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	xerrors "golang.org/x/xerrors"
+)
+
+var _ = xerrors.Errorf
+var _ = cid.Undef
+var _ = math.E
+var _ = sort.Sort
+
+func (t *VRFReceipt) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{171}); err != nil {
+		return err
+	}
+
+	// t.Pers (gamevrf.DomainSeparationTag) (int64)
+	if len("Pers") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Pers\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Pers"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("Pers")); err != nil {
+		return err
+	}
+
+	if t.Pers >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Pers)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Pers-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Proof ([]uint8) (slice)
+	if len("Proof") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Proof\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proof"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("Proof")); err != nil {
+		return err
+	}
+
+	if len(t.Proof) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Proof was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Proof))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.Proof[:]); err != nil {
+		return err
+	}
+
+	// t.RBase ([]uint8) (slice)
+	if len("RBase") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RBase\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RBase"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("RBase")); err != nil {
+		return err
+	}
+
+	if len(t.RBase) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.RBase was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.RBase))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.RBase[:]); err != nil {
+		return err
+	}
+
+	// t.Height (uint64) (uint64)
+	if len("Height") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Height\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Height"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("Height")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Height)); err != nil {
+		return err
+	}
+
+	// t.Signer (address.Address) (struct)
+	if len("Signer") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Signer\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Signer"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("Signer")); err != nil {
+		return err
+	}
+
+	if err := t.Signer.MarshalCBOR(cw); err != nil {
+		return err
+	}
+
+	// t.Entropy ([]uint8) (slice)
+	if len("Entropy") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Entropy\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Entropy"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("Entropy")); err != nil {
+		return err
+	}
+
+	if len(t.Entropy) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Entropy was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Entropy))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.Entropy[:]); err != nil {
+		return err
+	}
+
+	// t.Version (uint64) (uint64)
+	if len("Version") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Version\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Version"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("Version")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Version)); err != nil {
+		return err
+	}
+
+	// t.BaseKind (gamevrf.BaseKind) (uint64)
+	if len("BaseKind") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"BaseKind\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("BaseKind"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("BaseKind")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.BaseKind)); err != nil {
+		return err
+	}
+
+	// t.PrevRBase ([]uint8) (slice)
+	if len("PrevRBase") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"PrevRBase\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("PrevRBase"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("PrevRBase")); err != nil {
+		return err
+	}
+
+	if len(t.PrevRBase) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.PrevRBase was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.PrevRBase))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.PrevRBase[:]); err != nil {
+		return err
+	}
+
+	// t.TipSetCids ([]cid.Cid) (slice)
+	if len("TipSetCids") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TipSetCids\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TipSetCids"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("TipSetCids")); err != nil {
+		return err
+	}
+
+	if len(t.TipSetCids) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.TipSetCids was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.TipSetCids))); err != nil {
+		return err
+	}
+	for _, v := range t.TipSetCids {
+		if err := cbg.WriteCid(w, v); err != nil {
+			return xerrors.Errorf("failed writing cid field t.TipSetCids: %w", err)
+		}
+	}
+
+	// t.EntropyHash ([]uint8) (slice)
+	if len("EntropyHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"EntropyHash\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("EntropyHash"))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(string("EntropyHash")); err != nil {
+		return err
+	}
+
+	if len(t.EntropyHash) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.EntropyHash was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.EntropyHash))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.EntropyHash[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *VRFReceipt) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = VRFReceipt{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("VRFReceipt: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Pers (gamevrf.DomainSeparationTag) (int64)
+		case "Pers":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Pers = DomainSeparationTag(extraI)
+			}
+			// t.Proof ([]uint8) (slice)
+		case "Proof":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Proof: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Proof = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.Proof[:]); err != nil {
+				return err
+			}
+			// t.RBase ([]uint8) (slice)
+		case "RBase":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.RBase: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.RBase = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.RBase[:]); err != nil {
+				return err
+			}
+			// t.Height (uint64) (uint64)
+		case "Height":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Height = uint64(extra)
+
+			}
+			// t.Signer (address.Address) (struct)
+		case "Signer":
+
+			{
+
+				if err := t.Signer.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.Signer: %w", err)
+				}
+
+			}
+			// t.Entropy ([]uint8) (slice)
+		case "Entropy":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Entropy: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Entropy = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.Entropy[:]); err != nil {
+				return err
+			}
+			// t.Version (uint64) (uint64)
+		case "Version":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Version = uint64(extra)
+
+			}
+			// t.BaseKind (gamevrf.BaseKind) (uint64)
+		case "BaseKind":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.BaseKind = BaseKind(extra)
+
+			}
+			// t.PrevRBase ([]uint8) (slice)
+		case "PrevRBase":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.PrevRBase: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.PrevRBase = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.PrevRBase[:]); err != nil {
+				return err
+			}
+			// t.TipSetCids ([]cid.Cid) (slice)
+		case "TipSetCids":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.TipSetCids: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.TipSetCids = make([]cid.Cid, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				c, err := cbg.ReadCid(cr)
+				if err != nil {
+					return xerrors.Errorf("reading cid field t.TipSetCids failed: %w", err)
+				}
+				t.TipSetCids[i] = c
+			}
+
+			// t.EntropyHash ([]uint8) (slice)
+		case "EntropyHash":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.EntropyHash: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.EntropyHash = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.EntropyHash[:]); err != nil {
+				return err
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,349 @@
+// Package commitreveal lets players contribute to the VRF entropy of a round: players commit to the hash
+// of a client seed before the round, reveal the seed afterwards and the revealed seeds are mixed with the
+// server entropy, so the server can't choose the inputs of a round alone
+package commitreveal
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/minio/blake2b-simd"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// TRANSCRIPT_VERSION is the version of the transcript and of the entropy mixing
+	TRANSCRIPT_VERSION = 1
+	// SEED_SIZE is the size of seeds made by NewSeed
+	SEED_SIZE = 32
+	// MIN_SEED_SIZE is the smallest seed accepted, shorter seeds could be brute forced from their commitment
+	MIN_SEED_SIZE = 16
+
+	commitContext  = "titan-game-sdk/commit/v1"
+	entropyContext = "titan-game-sdk/commit-reveal-entropy/v1"
+	// serverID is the player id the server entropy is committed under, player ids can't be empty
+	serverID = ""
+)
+
+// NewSeed returns a random client seed
+func NewSeed() ([]byte, error) {
+	seed := make([]byte, SEED_SIZE)
+	_, err := rand.Read(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return seed, nil
+}
+
+// Commit returns the commitment of playerID to seed in round roundID, it is bound to the round and the
+// player so a commitment can't be copied by another player or replayed in another round
+func Commit(roundID, playerID string, seed []byte) []byte {
+	h := blake2b.New256()
+	writeBytes(h, []byte(commitContext))
+	writeBytes(h, []byte(roundID))
+	writeBytes(h, []byte(playerID))
+	writeBytes(h, seed)
+
+	return h.Sum(nil)
+}
+
+// Commitment is the commitment of a player
+type Commitment struct {
+	PlayerID string
+	Hash     []byte
+}
+
+// Reveal is the seed revealed by a player
+type Reveal struct {
+	PlayerID string
+	Seed     []byte
+}
+
+// Transcript records a round so anyone can check every commitment against its reveal and recompute the entropy
+type Transcript struct {
+	Version          int
+	RoundID          string
+	ServerCommitment []byte
+	ServerEntropy    []byte
+	Commitments      []Commitment // sorted by player id
+	Reveals          []Reveal     // sorted by player id
+	Forfeits         []string     // players who committed but never revealed, sorted
+}
+
+// Round collects the commitments and reveals of a round
+type Round struct {
+	lck           sync.Mutex
+	id            string
+	serverEntropy []byte
+	commitments   map[string][]byte
+	reveals       map[string][]byte
+	forfeits      map[string]bool
+	closed        bool
+}
+
+// NewRound starts the commit phase of round id, serverEntropy is fixed now and its commitment
+// should be published to the players together with the round id. serverEntropy must be at least
+// MIN_SEED_SIZE bytes, a shorter one could be brute forced from its commitment by the players
+func NewRound(id string, serverEntropy []byte) (*Round, error) {
+	if len(serverEntropy) < MIN_SEED_SIZE {
+		return nil, xerrors.Errorf("NewRound server entropy of %d bytes is shorter than %d", len(serverEntropy), MIN_SEED_SIZE)
+	}
+
+	return &Round{
+		id:            id,
+		serverEntropy: append([]byte(nil), serverEntropy...),
+		commitments:   make(map[string][]byte),
+		reveals:       make(map[string][]byte),
+		forfeits:      make(map[string]bool),
+	}, nil
+}
+
+// ID returns the round id
+func (r *Round) ID() string {
+	return r.id
+}
+
+// ServerCommitment returns the commitment of the server entropy
+func (r *Round) ServerCommitment() []byte {
+	return Commit(r.id, serverID, r.serverEntropy)
+}
+
+// AddCommitment registers the commitment of a player, only during the commit phase
+func (r *Round) AddCommitment(playerID string, hash []byte) error {
+	if playerID == serverID {
+		return xerrors.New("AddCommitment empty player id")
+	}
+
+	if len(hash) != 32 {
+		return xerrors.Errorf("AddCommitment invalid commitment size %d", len(hash))
+	}
+
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if r.closed {
+		return xerrors.Errorf("AddCommitment round %s commit phase is over", r.id)
+	}
+
+	if _, ok := r.commitments[playerID]; ok {
+		return xerrors.Errorf("AddCommitment player %s already committed", playerID)
+	}
+
+	r.commitments[playerID] = append([]byte(nil), hash...)
+
+	return nil
+}
+
+// Close ends the commit phase, reveals are accepted from now on
+func (r *Round) Close() {
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	r.closed = true
+}
+
+// AddReveal checks the seed of a player against its commitment and keeps it
+func (r *Round) AddReveal(playerID string, seed []byte) error {
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if !r.closed {
+		return xerrors.Errorf("AddReveal round %s is still in the commit phase", r.id)
+	}
+
+	commitment, ok := r.commitments[playerID]
+	if !ok {
+		return xerrors.Errorf("AddReveal player %s didn't commit", playerID)
+	}
+
+	if r.forfeits[playerID] {
+		return xerrors.Errorf("AddReveal player %s forfeited", playerID)
+	}
+
+	if _, ok := r.reveals[playerID]; ok {
+		return xerrors.Errorf("AddReveal player %s already revealed", playerID)
+	}
+
+	if len(seed) < MIN_SEED_SIZE {
+		return xerrors.Errorf("AddReveal seed of %d bytes is shorter than %d", len(seed), MIN_SEED_SIZE)
+	}
+
+	if !bytes.Equal(Commit(r.id, playerID, seed), commitment) {
+		return xerrors.Errorf("AddReveal seed of player %s doesn't match its commitment", playerID)
+	}
+
+	r.reveals[playerID] = append([]byte(nil), seed...)
+
+	return nil
+}
+
+// Missing returns the players who committed but neither revealed nor forfeited, sorted
+func (r *Round) Missing() []string {
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	var missing []string
+	for p := range r.commitments {
+		if _, ok := r.reveals[p]; !ok && !r.forfeits[p] {
+			missing = append(missing, p)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing
+}
+
+// Forfeit excludes a player who doesn't reveal from the entropy, how the player is penalized is up to the game.
+// Withholding a reveal is the only influence a player has on the round, games should make it costly.
+func (r *Round) Forfeit(playerID string) error {
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if !r.closed {
+		return xerrors.Errorf("Forfeit round %s is still in the commit phase", r.id)
+	}
+
+	if _, ok := r.commitments[playerID]; !ok {
+		return xerrors.Errorf("Forfeit player %s didn't commit", playerID)
+	}
+
+	if _, ok := r.reveals[playerID]; ok {
+		return xerrors.Errorf("Forfeit player %s already revealed", playerID)
+	}
+
+	r.forfeits[playerID] = true
+
+	return nil
+}
+
+// Transcript returns the record of the round, every committed player must have revealed or forfeited
+func (r *Round) Transcript() (*Transcript, error) {
+	if missing := r.Missing(); len(missing) > 0 {
+		return nil, xerrors.Errorf("Transcript players %v haven't revealed", missing)
+	}
+
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if !r.closed {
+		return nil, xerrors.Errorf("Transcript round %s is still in the commit phase", r.id)
+	}
+
+	t := &Transcript{
+		Version:          TRANSCRIPT_VERSION,
+		RoundID:          r.id,
+		ServerCommitment: Commit(r.id, serverID, r.serverEntropy),
+		ServerEntropy:    append([]byte(nil), r.serverEntropy...),
+	}
+
+	for p, hash := range r.commitments {
+		t.Commitments = append(t.Commitments, Commitment{PlayerID: p, Hash: hash})
+		if seed, ok := r.reveals[p]; ok {
+			t.Reveals = append(t.Reveals, Reveal{PlayerID: p, Seed: seed})
+		} else {
+			t.Forfeits = append(t.Forfeits, p)
+		}
+	}
+
+	sort.Slice(t.Commitments, func(i, j int) bool { return t.Commitments[i].PlayerID < t.Commitments[j].PlayerID })
+	sort.Slice(t.Reveals, func(i, j int) bool { return t.Reveals[i].PlayerID < t.Reveals[j].PlayerID })
+	sort.Strings(t.Forfeits)
+
+	return t, nil
+}
+
+// Entropy returns the VRF entropy of the round once every player revealed or forfeited
+func (r *Round) Entropy() ([]byte, error) {
+	t, err := r.Transcript()
+	if err != nil {
+		return nil, err
+	}
+
+	return t.entropy(), nil
+}
+
+// VerifyTranscript checks the server entropy and every reveal against their commitments, that every
+// other commitment is forfeited, and returns the entropy of the round
+func VerifyTranscript(t *Transcript) ([]byte, error) {
+	if t.Version != TRANSCRIPT_VERSION {
+		return nil, xerrors.Errorf("VerifyTranscript unsupported transcript version %d", t.Version)
+	}
+
+	if len(t.ServerEntropy) < MIN_SEED_SIZE {
+		return nil, xerrors.Errorf("VerifyTranscript server entropy of %d bytes is shorter than %d", len(t.ServerEntropy), MIN_SEED_SIZE)
+	}
+
+	if !bytes.Equal(Commit(t.RoundID, serverID, t.ServerEntropy), t.ServerCommitment) {
+		return nil, xerrors.New("VerifyTranscript server entropy doesn't match its commitment")
+	}
+
+	commitments := make(map[string][]byte)
+	for i, c := range t.Commitments {
+		if c.PlayerID == serverID || (i > 0 && c.PlayerID <= t.Commitments[i-1].PlayerID) {
+			return nil, xerrors.New("VerifyTranscript commitments aren't sorted by unique player ids")
+		}
+		commitments[c.PlayerID] = c.Hash
+	}
+
+	settled := make(map[string]bool)
+	for i, rv := range t.Reveals {
+		if i > 0 && rv.PlayerID <= t.Reveals[i-1].PlayerID {
+			return nil, xerrors.New("VerifyTranscript reveals aren't sorted by unique player ids")
+		}
+
+		hash, ok := commitments[rv.PlayerID]
+		if !ok {
+			return nil, xerrors.Errorf("VerifyTranscript player %s revealed without committing", rv.PlayerID)
+		}
+
+		if len(rv.Seed) < MIN_SEED_SIZE || !bytes.Equal(Commit(t.RoundID, rv.PlayerID, rv.Seed), hash) {
+			return nil, xerrors.Errorf("VerifyTranscript seed of player %s doesn't match its commitment", rv.PlayerID)
+		}
+		settled[rv.PlayerID] = true
+	}
+
+	for _, p := range t.Forfeits {
+		if _, ok := commitments[p]; !ok || settled[p] {
+			return nil, xerrors.Errorf("VerifyTranscript invalid forfeit of player %s", p)
+		}
+		settled[p] = true
+	}
+
+	if len(settled) != len(commitments) {
+		return nil, xerrors.New("VerifyTranscript some commitments are neither revealed nor forfeited")
+	}
+
+	return t.entropy(), nil
+}
+
+// entropy mixes the server entropy with the revealed seeds, blake2b-256 over the length prefixed
+// round id, server entropy, and player ids and seeds in player id order
+func (t *Transcript) entropy() []byte {
+	h := blake2b.New256()
+	writeBytes(h, []byte(entropyContext))
+	writeBytes(h, []byte(t.RoundID))
+	writeBytes(h, t.ServerEntropy)
+
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(t.Reveals)))
+	h.Write(n[:])
+	for _, rv := range t.Reveals {
+		writeBytes(h, []byte(rv.PlayerID))
+		writeBytes(h, rv.Seed)
+	}
+
+	return h.Sum(nil)
+}
+
+// writeBytes writes a 8 bytes big endian length followed by b
+func writeBytes(w io.Writer, b []byte) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+	w.Write(n[:])
+	w.Write(b)
+}
@@ -1,5 +1,13 @@
 package gamevrf
 
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
 // Specifies a domain for randomness generation.
 type DomainSeparationTag int64
 
@@ -12,3 +20,146 @@ const (
 	// dedicate for players
 	DomainSeparationTag_GamePlayers
 )
+
+const (
+	// DomainSeparationTag_GameDefinedMin is the first tag games can register
+	DomainSeparationTag_GameDefinedMin DomainSeparationTag = 1 << 16
+	// DomainSeparationTag_GameDefinedMax is the last tag games can register
+	DomainSeparationTag_GameDefinedMax DomainSeparationTag = 1<<32 - 1
+)
+
+// builtinTagNames are the names of the tags defined by the sdk
+var builtinTagNames = map[DomainSeparationTag]string{
+	DomainSeparationTag_GameBasic:   "GameBasic",
+	DomainSeparationTag_GameRound:   "GameRound",
+	DomainSeparationTag_GameLottery: "GameLottery",
+	DomainSeparationTag_GamePlayers: "GamePlayers",
+}
+
+// DefaultTagRegistry holds the tags registered by RegisterDomainSeparationTag, used by String and ParseDomainSeparationTag
+var DefaultTagRegistry = NewTagRegistry()
+
+// String returns the name of a builtin tag or of a tag registered in DefaultTagRegistry
+func (t DomainSeparationTag) String() string {
+	if name, ok := builtinTagNames[t]; ok {
+		return name
+	}
+
+	if name, ok := DefaultTagRegistry.Name(t); ok {
+		return name
+	}
+
+	return "DomainSeparationTag(" + strconv.FormatInt(int64(t), 10) + ")"
+}
+
+// ParseDomainSeparationTag parses the name of a builtin tag, of a tag registered in DefaultTagRegistry, or a decimal tag
+func ParseDomainSeparationTag(s string) (DomainSeparationTag, error) {
+	for tag, name := range builtinTagNames {
+		if name == s {
+			return tag, nil
+		}
+	}
+
+	if tag, ok := DefaultTagRegistry.Tag(s); ok {
+		return tag, nil
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(s, "DomainSeparationTag("), ")"), 10, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("ParseDomainSeparationTag unknown tag %q", s)
+	}
+
+	return DomainSeparationTag(n), nil
+}
+
+// RegisterDomainSeparationTag registers a game defined tag in DefaultTagRegistry
+func RegisterDomainSeparationTag(name string, tag DomainSeparationTag) error {
+	return DefaultTagRegistry.Register(name, tag)
+}
+
+// TagRegistry holds named game defined tags, a GameVRF created with TagRegistryOption
+// only generates and verifies VRFs of the tags in its registry
+type TagRegistry struct {
+	lck    sync.RWMutex
+	names  map[DomainSeparationTag]string
+	byName map[string]DomainSeparationTag
+}
+
+// NewTagRegistry creates an empty registry
+func NewTagRegistry() *TagRegistry {
+	return &TagRegistry{
+		names:  make(map[DomainSeparationTag]string),
+		byName: make(map[string]DomainSeparationTag),
+	}
+}
+
+// Register adds a tag in the range [DomainSeparationTag_GameDefinedMin, DomainSeparationTag_GameDefinedMax],
+// names and tags can be registered only once
+func (r *TagRegistry) Register(name string, tag DomainSeparationTag) error {
+	if tag < DomainSeparationTag_GameDefinedMin || tag > DomainSeparationTag_GameDefinedMax {
+		return xerrors.Errorf("Register tag %d out of the game defined range [%d, %d]", tag, DomainSeparationTag_GameDefinedMin, DomainSeparationTag_GameDefinedMax)
+	}
+
+	if name == "" || strings.ContainsAny(name, "() \t\n") {
+		return xerrors.Errorf("Register invalid tag name %q", name)
+	}
+
+	for _, builtin := range builtinTagNames {
+		if builtin == name {
+			return xerrors.Errorf("Register tag name %q is builtin", name)
+		}
+	}
+
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if other, ok := r.names[tag]; ok {
+		return xerrors.Errorf("Register tag %d already registered as %q", tag, other)
+	}
+
+	if other, ok := r.byName[name]; ok {
+		return xerrors.Errorf("Register tag name %q already registered as %d", name, other)
+	}
+
+	r.names[tag] = name
+	r.byName[name] = tag
+
+	return nil
+}
+
+// MustRegister is like Register but panics on error, for registering tags in package variables
+func (r *TagRegistry) MustRegister(name string, tag DomainSeparationTag) DomainSeparationTag {
+	err := r.Register(name, tag)
+	if err != nil {
+		panic(err)
+	}
+
+	return tag
+}
+
+// Name returns the name tag was registered with
+func (r *TagRegistry) Name(tag DomainSeparationTag) (string, bool) {
+	r.lck.RLock()
+	defer r.lck.RUnlock()
+
+	name, ok := r.names[tag]
+	return name, ok
+}
+
+// Tag returns the tag registered with name
+func (r *TagRegistry) Tag(name string) (DomainSeparationTag, bool) {
+	r.lck.RLock()
+	defer r.lck.RUnlock()
+
+	tag, ok := r.byName[name]
+	return tag, ok
+}
+
+// Check returns an error if tag isn't registered
+func (r *TagRegistry) Check(tag DomainSeparationTag) error {
+	if _, ok := r.Name(tag); !ok {
+		return xerrors.Errorf("domain separation tag %s isn't registered", tag)
+	}
+
+	return nil
+}
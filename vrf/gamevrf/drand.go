@@ -0,0 +1,543 @@
+package gamevrf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	bls "github.com/drand/kyber-bls12381"
+	sign "github.com/drand/kyber/sign/bls"
+	"golang.org/x/xerrors"
+)
+
+// BaseKind is the kind of randomness a VRF base is taken from
+type BaseKind uint64
+
+const (
+	// BaseKind_MinTicket is the min ticket VRFProof of a Filecoin tipset, VRFOut.Height is the tipset height
+	BaseKind_MinTicket BaseKind = iota
+	// BaseKind_Drand is the signature of a drand beacon, VRFOut.Height is the drand round
+	BaseKind_Drand
+)
+
+// String returns the name of the base kind
+func (k BaseKind) String() string {
+	switch k {
+	case BaseKind_MinTicket:
+		return "minticket"
+	case BaseKind_Drand:
+		return "drand"
+	default:
+		return "BaseKind(" + strconv.FormatUint(uint64(k), 10) + ")"
+	}
+}
+
+const (
+	// DRAND_SCHEME_CHAINED signs sha256(previous signature || round) on G2, the drand mainnet default chain
+	DRAND_SCHEME_CHAINED = "pedersen-bls-chained"
+	// DRAND_SCHEME_UNCHAINED signs sha256(round) on G2
+	DRAND_SCHEME_UNCHAINED = "pedersen-bls-unchained"
+	// DRAND_SCHEME_UNCHAINED_G1 signs sha256(round) on G1, the drand quicknet chain used by Filecoin
+	DRAND_SCHEME_UNCHAINED_G1 = "bls-unchained-g1-rfc9380"
+	// FILECOIN_MAINNET_GENESIS_TIME is the unix time of the Filecoin mainnet genesis block
+	FILECOIN_MAINNET_GENESIS_TIME = 1598306400
+)
+
+// DrandChainInfo describes a drand chain, beacons are verified against its public key
+type DrandChainInfo struct {
+	PublicKey   []byte
+	Period      time.Duration
+	GenesisTime int64
+	Hash        []byte
+	GroupHash   []byte
+	Scheme      string
+	BeaconID    string
+}
+
+// drandChainInfoJSON is the format of the drand /info endpoint
+type drandChainInfoJSON struct {
+	PublicKey   string `json:"public_key"`
+	Period      int64  `json:"period"`
+	GenesisTime int64  `json:"genesis_time"`
+	Hash        string `json:"hash"`
+	GroupHash   string `json:"groupHash"`
+	Scheme      string `json:"schemeID,omitempty"`
+	Metadata    struct {
+		BeaconID string `json:"beaconID,omitempty"`
+	} `json:"metadata"`
+}
+
+// MarshalJSON encodes the chain info in the format of the drand /info endpoint
+func (c *DrandChainInfo) MarshalJSON() ([]byte, error) {
+	v := drandChainInfoJSON{
+		PublicKey:   hex.EncodeToString(c.PublicKey),
+		Period:      int64(c.Period / time.Second),
+		GenesisTime: c.GenesisTime,
+		Hash:        hex.EncodeToString(c.Hash),
+		GroupHash:   hex.EncodeToString(c.GroupHash),
+		Scheme:      c.Scheme,
+	}
+	v.Metadata.BeaconID = c.BeaconID
+
+	return json.Marshal(&v)
+}
+
+// UnmarshalJSON decodes the chain info answered by the drand /info endpoint
+func (c *DrandChainInfo) UnmarshalJSON(b []byte) error {
+	var v drandChainInfoJSON
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+
+	info := DrandChainInfo{
+		Period:      time.Duration(v.Period) * time.Second,
+		GenesisTime: v.GenesisTime,
+		Scheme:      v.Scheme,
+		BeaconID:    v.Metadata.BeaconID,
+	}
+
+	if info.Scheme == "" {
+		info.Scheme = DRAND_SCHEME_CHAINED
+	}
+
+	for _, f := range []struct {
+		dst *[]byte
+		src string
+	}{{&info.PublicKey, v.PublicKey}, {&info.Hash, v.Hash}, {&info.GroupHash, v.GroupHash}} {
+		*f.dst, err = hex.DecodeString(f.src)
+		if err != nil {
+			return xerrors.Errorf("invalid drand chain info: %w", err)
+		}
+	}
+
+	*c = info
+	return nil
+}
+
+// ParseDrandChainInfo decodes the json chain info of a drand chain
+func ParseDrandChainInfo(b []byte) (*DrandChainInfo, error) {
+	var info DrandChainInfo
+	err := json.Unmarshal(b, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Period <= 0 || len(info.PublicKey) == 0 {
+		return nil, xerrors.New("ParseDrandChainInfo missing period or public key")
+	}
+
+	return &info, nil
+}
+
+// VerifyHash checks that Hash is the hash drand derives from the chain parameters, so an info matching a trusted
+// chain hash can't carry another public key
+func (c *DrandChainInfo) VerifyHash() error {
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, uint32(c.Period/time.Second))
+	_ = binary.Write(h, binary.BigEndian, c.GenesisTime)
+	h.Write(c.PublicKey)
+	h.Write(c.GroupHash)
+	if c.BeaconID != "" && c.BeaconID != "default" {
+		h.Write([]byte(c.BeaconID))
+	}
+
+	if !bytes.Equal(h.Sum(nil), c.Hash) {
+		return xerrors.Errorf("VerifyHash chain hash %x doesn't match the chain info", c.Hash)
+	}
+
+	return nil
+}
+
+// RoundAt returns the latest round emitted at t, 0 before the genesis
+func (c *DrandChainInfo) RoundAt(t time.Time) uint64 {
+	if t.Unix() < c.GenesisTime {
+		return 0
+	}
+
+	return uint64((t.Unix()-c.GenesisTime)/int64(c.Period/time.Second)) + 1
+}
+
+// RoundTime returns the time round is emitted
+func (c *DrandChainInfo) RoundTime(round uint64) time.Time {
+	if round == 0 {
+		return time.Unix(c.GenesisTime, 0)
+	}
+
+	return time.Unix(c.GenesisTime, 0).Add(time.Duration(round-1) * c.Period)
+}
+
+// VerifyBeacon checks the beacon signature with the chain public key
+func (c *DrandChainInfo) VerifyBeacon(b *DrandBeacon) error {
+	if b.Round == 0 {
+		return xerrors.New("VerifyBeacon round 0 has no beacon")
+	}
+
+	var round [8]byte
+	binary.BigEndian.PutUint64(round[:], b.Round)
+
+	h := sha256.New()
+	suite := bls.NewBLS12381Suite()
+	var scheme = sign.NewSchemeOnG2(suite)
+	var pub = suite.G1().Point()
+	switch c.Scheme {
+	case DRAND_SCHEME_CHAINED:
+		if len(b.PreviousSignature) == 0 {
+			return xerrors.Errorf("VerifyBeacon round %d of a chained scheme needs the previous signature", b.Round)
+		}
+		h.Write(b.PreviousSignature)
+	case DRAND_SCHEME_UNCHAINED:
+	case DRAND_SCHEME_UNCHAINED_G1:
+		scheme = sign.NewSchemeOnG1(suite)
+		pub = suite.G2().Point()
+	default:
+		return xerrors.Errorf("VerifyBeacon unsupported drand scheme %q", c.Scheme)
+	}
+	h.Write(round[:])
+
+	err := pub.UnmarshalBinary(c.PublicKey)
+	if err != nil {
+		return xerrors.Errorf("VerifyBeacon invalid chain public key: %w", err)
+	}
+
+	err = scheme.Verify(pub, h.Sum(nil), b.Signature)
+	if err != nil {
+		return xerrors.Errorf("VerifyBeacon round %d: %w", b.Round, err)
+	}
+
+	return nil
+}
+
+// DrandBeacon is a drand round, its signature is the VRF base
+type DrandBeacon struct {
+	Round             uint64
+	Signature         []byte
+	PreviousSignature []byte // only set for chained schemes
+}
+
+// drandBeaconJSON is the format of the drand /public endpoints
+type drandBeaconJSON struct {
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness,omitempty"`
+	Signature         string `json:"signature"`
+	PreviousSignature string `json:"previous_signature,omitempty"`
+}
+
+// Randomness returns the randomness of the round, sha256 of the signature
+func (b *DrandBeacon) Randomness() [32]byte {
+	return sha256.Sum256(b.Signature)
+}
+
+// MarshalJSON encodes the beacon in the format of the drand /public endpoints
+func (b *DrandBeacon) MarshalJSON() ([]byte, error) {
+	r := b.Randomness()
+	return json.Marshal(&drandBeaconJSON{
+		Round:             b.Round,
+		Randomness:        hex.EncodeToString(r[:]),
+		Signature:         hex.EncodeToString(b.Signature),
+		PreviousSignature: hex.EncodeToString(b.PreviousSignature),
+	})
+}
+
+// UnmarshalJSON decodes a beacon answered by the drand /public endpoints
+func (b *DrandBeacon) UnmarshalJSON(data []byte) error {
+	var v drandBeaconJSON
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	sig, err := hex.DecodeString(v.Signature)
+	if err != nil {
+		return xerrors.Errorf("invalid drand beacon signature: %w", err)
+	}
+
+	prev, err := hex.DecodeString(v.PreviousSignature)
+	if err != nil {
+		return xerrors.Errorf("invalid drand beacon previous signature: %w", err)
+	}
+
+	*b = DrandBeacon{Round: v.Round, Signature: sig}
+	if len(prev) > 0 {
+		b.PreviousSignature = prev
+	}
+
+	return nil
+}
+
+// DrandSource serves drand beacons, they are verified by the caller against the chain info
+type DrandSource interface {
+	// Beacon returns the beacon of round, the latest one if round is 0
+	Beacon(ctx context.Context, round uint64) (*DrandBeacon, error)
+}
+
+// DrandHTTPSource fetches beacons from the drand HTTP API
+type DrandHTTPSource struct {
+	url       string
+	chainHash string
+	client    *http.Client
+}
+
+// NewDrandHTTPSource creates a source for the chain with hash chainHash served at url, such as https://api.drand.sh,
+// an empty chainHash selects the default chain of the server and a nil client http.DefaultClient
+func NewDrandHTTPSource(url string, chainHash string, client *http.Client) *DrandHTTPSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &DrandHTTPSource{
+		url:       strings.TrimRight(url, "/"),
+		chainHash: chainHash,
+		client:    client,
+	}
+}
+
+// ChainInfo fetches the chain info, it is checked against the chain hash of the source if set,
+// otherwise it must be checked against a trusted chain hash before it is used to verify beacons
+func (s *DrandHTTPSource) ChainInfo(ctx context.Context) (*DrandChainInfo, error) {
+	b, err := s.get(ctx, "info")
+	if err != nil {
+		return nil, xerrors.Errorf("ChainInfo %w", err)
+	}
+
+	info, err := ParseDrandChainInfo(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.chainHash != "" {
+		if hex.EncodeToString(info.Hash) != strings.ToLower(s.chainHash) {
+			return nil, xerrors.Errorf("ChainInfo chain hash %x isn't %s", info.Hash, s.chainHash)
+		}
+
+		if err := info.VerifyHash(); err != nil {
+			return nil, xerrors.Errorf("ChainInfo %w", err)
+		}
+	}
+
+	return info, nil
+}
+
+// Beacon implements DrandSource
+func (s *DrandHTTPSource) Beacon(ctx context.Context, round uint64) (*DrandBeacon, error) {
+	path := "public/latest"
+	if round > 0 {
+		path = "public/" + strconv.FormatUint(round, 10)
+	}
+
+	b, err := s.get(ctx, path)
+	if err != nil {
+		return nil, xerrors.Errorf("Beacon %w", err)
+	}
+
+	var beacon DrandBeacon
+	err = json.Unmarshal(b, &beacon)
+	if err != nil {
+		return nil, xerrors.Errorf("Beacon invalid response: %w", err)
+	}
+
+	if round > 0 && beacon.Round != round {
+		return nil, xerrors.Errorf("Beacon requested round %d, got %d", round, beacon.Round)
+	}
+
+	return &beacon, nil
+}
+
+// get requests path of the chain
+func (s *DrandHTTPSource) get(ctx context.Context, path string) ([]byte, error) {
+	url := s.url + "/"
+	if s.chainHash != "" {
+		url += s.chainHash + "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("%s status %d: %s", url+path, rsp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	return b, nil
+}
+
+// TipSetBeaconSource reads beacons from the beacon entries of Filecoin block headers
+type TipSetBeaconSource struct {
+	provider       TipSetProvider
+	info           *DrandChainInfo
+	filGenesisTime int64
+}
+
+// NewTipSetBeaconSource creates a source reading the beacon entries of the tipsets served by provider,
+// info is the drand chain the network includes and filGenesisTime the unix time of the network genesis
+func NewTipSetBeaconSource(provider TipSetProvider, info *DrandChainInfo, filGenesisTime int64) *TipSetBeaconSource {
+	return &TipSetBeaconSource{
+		provider:       provider,
+		info:           info,
+		filGenesisTime: filGenesisTime,
+	}
+}
+
+// Beacon implements DrandSource, the latest beacon is the last entry of the chain head
+func (s *TipSetBeaconSource) Beacon(ctx context.Context, round uint64) (*DrandBeacon, error) {
+	if round == 0 {
+		head, err := s.provider.ChainHead(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("Beacon ChainHead failed: %w", err)
+		}
+
+		return s.latest(ctx, head)
+	}
+
+	// the tipset at epoch e includes the round emitted one epoch before its own timestamp
+	epochDuration := int64(FILECOIN_EPOCH_DURATION)
+	emitted := s.info.RoundTime(round).Unix()
+	epoch := (emitted-s.filGenesisTime)/epochDuration + 1
+	if epoch < 0 {
+		epoch = 0
+	}
+
+	for h := epoch; h < epoch+TICKET_BEACON_SEARCH_LIMIT; h++ {
+		ts, err := s.provider.ChainGetTipSetByHeight(ctx, h)
+		if err != nil {
+			return nil, xerrors.Errorf("Beacon get tipset failed: %w", err)
+		}
+
+		if ts.Height() != uint64(h) || len(ts.Blocks()) == 0 {
+			continue
+		}
+
+		entries := ts.Blocks()[0].BeaconEntries
+		for i, e := range entries {
+			if e.Round == round {
+				return s.beacon(ctx, ts, entries, i)
+			}
+		}
+
+		if len(entries) > 0 && entries[len(entries)-1].Round > round {
+			break
+		}
+	}
+
+	return nil, xerrors.Errorf("Beacon round %d isn't included in the chain around epoch %d", round, epoch)
+}
+
+// latest returns the last beacon entry at or below ts
+func (s *TipSetBeaconSource) latest(ctx context.Context, ts *filrpc.TipSet) (*DrandBeacon, error) {
+	for i := 0; i < TICKET_BEACON_SEARCH_LIMIT; i++ {
+		entries := ts.Blocks()[0].BeaconEntries
+		if len(entries) > 0 {
+			return s.beacon(ctx, ts, entries, len(entries)-1)
+		}
+
+		if ts.Height() == 0 {
+			break
+		}
+
+		var err error
+		ts, err = s.provider.ChainGetTipSetByHeight(ctx, int64(ts.Height())-1)
+		if err != nil {
+			return nil, xerrors.Errorf("get tipset searching beacon entry failed: %w", err)
+		}
+	}
+
+	return nil, xerrors.Errorf("no beacon entry found within %d tipsets of height %d", TICKET_BEACON_SEARCH_LIMIT, ts.Height())
+}
+
+// beacon converts entries[i] of ts, looking up the previous signature for chained schemes
+func (s *TipSetBeaconSource) beacon(ctx context.Context, ts *filrpc.TipSet, entries []filrpc.BeaconEntry, i int) (*DrandBeacon, error) {
+	b := &DrandBeacon{Round: entries[i].Round, Signature: entries[i].Data}
+	if s.info.Scheme != DRAND_SCHEME_CHAINED {
+		return b, nil
+	}
+
+	if i > 0 {
+		if entries[i-1].Round != b.Round-1 {
+			return nil, xerrors.Errorf("round %d isn't preceded by its previous round in height %d", b.Round, ts.Height())
+		}
+		b.PreviousSignature = entries[i-1].Data
+		return b, nil
+	}
+
+	if ts.Height() == 0 {
+		return nil, xerrors.Errorf("no previous round of round %d", b.Round)
+	}
+
+	parent, err := s.provider.ChainGetTipSetByHeight(ctx, int64(ts.Height())-1)
+	if err != nil {
+		return nil, xerrors.Errorf("get parent tipset failed: %w", err)
+	}
+
+	prev, err := s.latest(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if prev.Round != b.Round-1 {
+		return nil, xerrors.Errorf("round %d isn't preceded by its previous round on chain", b.Round)
+	}
+	b.PreviousSignature = prev.Signature
+
+	return b, nil
+}
+
+// drandBase fetches and verifies the beacon of round, the latest one if round is 0
+func (g *GameVRF) drandBase(ctx context.Context, round uint64) (*DrandBeacon, error) {
+	if g.drandSource == nil || g.drandInfo == nil {
+		return nil, xerrors.New("drand base isn't configured, use DrandBaseOption")
+	}
+
+	b, err := g.drandSource.Beacon(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.drandInfo.VerifyBeacon(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// generateDrandVRF generates a VRF over the latest drand beacon
+func (g *GameVRF) generateDrandVRF(ctx context.Context, pers DomainSeparationTag, signer Signer, entropy []byte) (*VRFOut, *VRFMeta, error) {
+	b, err := g.drandBase(ctx, 0)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("GenerateVRF drand base failed: %w", err)
+	}
+
+	vrf, err := GenerateVRFWithSigner(ctx, pers, signer, b.Signature, b.Round, entropy)
+	if err != nil {
+		return nil, nil, err
+	}
+	vrf.BaseKind = BaseKind_Drand
+
+	return vrf, &VRFMeta{
+		BaseKind:        BaseKind_Drand,
+		RequestedHeight: b.Round,
+		BaseHeight:      b.Round,
+	}, nil
+}
@@ -0,0 +1,405 @@
+// Package entropy builds the VRF entropy of a game round with a canonical, versioned encoding,
+// the same round always gives the same entropy whatever the order players and fields were added in
+package entropy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ENTROPY_VERSION is the version of the encoding produced by Encode
+	ENTROPY_VERSION = 1
+	// MAX_FIELD_SIZE bounds the size of every string, name and value
+	MAX_FIELD_SIZE = 1 << 20
+	// PLAYER_ID_SEPARATOR joins the player ids stored on chain, player ids can't contain it
+	PLAYER_ID_SEPARATOR = ","
+)
+
+// FieldType is the type of an extra field
+type FieldType byte
+
+const (
+	FieldString FieldType = iota + 1
+	FieldBytes
+	FieldUint
+	FieldInt
+	FieldBool
+)
+
+// String returns the name of the type
+func (t FieldType) String() string {
+	switch t {
+	case FieldString:
+		return "string"
+	case FieldBytes:
+		return "bytes"
+	case FieldUint:
+		return "uint"
+	case FieldInt:
+		return "int"
+	case FieldBool:
+		return "bool"
+	default:
+		return "unknown"
+	}
+}
+
+// Field is an extra typed field of a round, Value holds the canonical encoding of the value
+type Field struct {
+	Name  string
+	Type  FieldType
+	Value []byte
+}
+
+// Uint returns the value of a FieldUint field
+func (f Field) Uint() (uint64, error) {
+	if f.Type != FieldUint || len(f.Value) != 8 {
+		return 0, xerrors.Errorf("field %s is a %s, not a uint", f.Name, f.Type)
+	}
+
+	return binary.BigEndian.Uint64(f.Value), nil
+}
+
+// Int returns the value of a FieldInt field
+func (f Field) Int() (int64, error) {
+	if f.Type != FieldInt || len(f.Value) != 8 {
+		return 0, xerrors.Errorf("field %s is a %s, not an int", f.Name, f.Type)
+	}
+
+	return int64(binary.BigEndian.Uint64(f.Value)), nil
+}
+
+// Bool returns the value of a FieldBool field
+func (f Field) Bool() (bool, error) {
+	if f.Type != FieldBool || len(f.Value) != 1 {
+		return false, xerrors.Errorf("field %s is a %s, not a bool", f.Name, f.Type)
+	}
+
+	return f.Value[0] == 1, nil
+}
+
+// validate checks the value has the canonical encoding of its type
+func (f Field) validate() error {
+	switch f.Type {
+	case FieldString, FieldBytes:
+	case FieldUint, FieldInt:
+		if len(f.Value) != 8 {
+			return xerrors.Errorf("field %s: %s value must be 8 bytes", f.Name, f.Type)
+		}
+	case FieldBool:
+		if len(f.Value) != 1 || f.Value[0] > 1 {
+			return xerrors.Errorf("field %s: bool value must be a single 0 or 1 byte", f.Name)
+		}
+	default:
+		return xerrors.Errorf("field %s: unknown type %d", f.Name, f.Type)
+	}
+
+	return nil
+}
+
+// GameRoundInfo has the fields of the GameRoundInfo stored by the game replay contract,
+// a contracts/api.GameRoundInfo converts to it directly
+type GameRoundInfo struct {
+	GameID    string
+	RoundID   string
+	ReplayID  string
+	PlayerIDs string
+}
+
+// Round is the information of a game round VRF entropy is built from
+type Round struct {
+	GameID    string
+	RoundID   string
+	ReplayID  string
+	PlayerIDs []string
+	Extra     []Field
+}
+
+// NewRound creates a round played by players
+func NewRound(gameID, roundID, replayID string, players ...string) *Round {
+	return &Round{
+		GameID:    gameID,
+		RoundID:   roundID,
+		ReplayID:  replayID,
+		PlayerIDs: append([]string(nil), players...),
+	}
+}
+
+// FromGameRoundInfo rebuilds a round from the information stored on chain, PlayerIDs is split on commas,
+// extra fields aren't stored on chain and must be added again by the verifier
+func FromGameRoundInfo(info GameRoundInfo) *Round {
+	var players []string
+	if info.PlayerIDs != "" {
+		players = strings.Split(info.PlayerIDs, PLAYER_ID_SEPARATOR)
+	}
+
+	return NewRound(info.GameID, info.RoundID, info.ReplayID, players...)
+}
+
+// GameRoundInfo returns the information to store on chain, players are sorted and joined with commas.
+// It only round trips through FromGameRoundInfo for rounds Encode accepts
+func (r *Round) GameRoundInfo() GameRoundInfo {
+	players := append([]string(nil), r.PlayerIDs...)
+	sort.Strings(players)
+
+	return GameRoundInfo{
+		GameID:    r.GameID,
+		RoundID:   r.RoundID,
+		ReplayID:  r.ReplayID,
+		PlayerIDs: strings.Join(players, PLAYER_ID_SEPARATOR),
+	}
+}
+
+// AddString adds a string field
+func (r *Round) AddString(name, v string) *Round {
+	r.Extra = append(r.Extra, Field{Name: name, Type: FieldString, Value: []byte(v)})
+	return r
+}
+
+// AddBytes adds a bytes field
+func (r *Round) AddBytes(name string, v []byte) *Round {
+	r.Extra = append(r.Extra, Field{Name: name, Type: FieldBytes, Value: append([]byte(nil), v...)})
+	return r
+}
+
+// AddUint adds an unsigned integer field
+func (r *Round) AddUint(name string, v uint64) *Round {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	r.Extra = append(r.Extra, Field{Name: name, Type: FieldUint, Value: b})
+	return r
+}
+
+// AddInt adds a signed integer field
+func (r *Round) AddInt(name string, v int64) *Round {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	r.Extra = append(r.Extra, Field{Name: name, Type: FieldInt, Value: b})
+	return r
+}
+
+// AddBool adds a boolean field
+func (r *Round) AddBool(name string, v bool) *Round {
+	var b byte
+	if v {
+		b = 1
+	}
+	r.Extra = append(r.Extra, Field{Name: name, Type: FieldBool, Value: []byte{b}})
+	return r
+}
+
+// Field returns the extra field called name
+func (r *Round) Field(name string) (Field, bool) {
+	for _, f := range r.Extra {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return Field{}, false
+}
+
+// Encode returns the canonical entropy of the round:
+//
+//	version byte
+//	GameID, RoundID, ReplayID
+//	player count, players in ascending order
+//	field count, fields in ascending name order as name, type byte, value
+//
+// counts are 4 bytes big endian and every string or value is prefixed by its 4 bytes big endian length.
+// Duplicate or empty players, players containing PLAYER_ID_SEPARATOR and duplicate field names are rejected
+// so the round is the same after a GameRoundInfo round trip.
+func (r *Round) Encode() ([]byte, error) {
+	players := append([]string(nil), r.PlayerIDs...)
+	sort.Strings(players)
+	for i, p := range players {
+		if p == "" {
+			return nil, xerrors.New("Encode empty player id")
+		}
+		if strings.Contains(p, PLAYER_ID_SEPARATOR) {
+			return nil, xerrors.Errorf("Encode player id %q contains %q", p, PLAYER_ID_SEPARATOR)
+		}
+		if i > 0 && players[i-1] == p {
+			return nil, xerrors.Errorf("Encode duplicate player id %q", p)
+		}
+	}
+
+	fields := append([]Field(nil), r.Extra...)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	for i, f := range fields {
+		if i > 0 && fields[i-1].Name == f.Name {
+			return nil, xerrors.Errorf("Encode duplicate field %q", f.Name)
+		}
+		if err := f.validate(); err != nil {
+			return nil, xerrors.Errorf("Encode %w", err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte(ENTROPY_VERSION)
+
+	for _, s := range []string{r.GameID, r.RoundID, r.ReplayID} {
+		if err := writeBytes(buf, []byte(s)); err != nil {
+			return nil, err
+		}
+	}
+
+	writeUint32(buf, uint32(len(players)))
+	for _, p := range players {
+		if err := writeBytes(buf, []byte(p)); err != nil {
+			return nil, err
+		}
+	}
+
+	writeUint32(buf, uint32(len(fields)))
+	for _, f := range fields {
+		if err := writeBytes(buf, []byte(f.Name)); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(byte(f.Type))
+		if err := writeBytes(buf, f.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode parses entropy produced by Encode, only canonical encodings are accepted
+// so the decoded round encodes back to the same bytes
+func Decode(b []byte) (*Round, error) {
+	d := &decoder{b: b}
+
+	version, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if version != ENTROPY_VERSION {
+		return nil, xerrors.Errorf("Decode unsupported entropy version %d", version)
+	}
+
+	r := &Round{}
+	for _, s := range []*string{&r.GameID, &r.RoundID, &r.ReplayID} {
+		v, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		*s = string(v)
+	}
+
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < n; i++ {
+		v, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		p := string(v)
+		if p == "" || strings.Contains(p, PLAYER_ID_SEPARATOR) || (i > 0 && p <= r.PlayerIDs[i-1]) {
+			return nil, xerrors.New("Decode players aren't canonical")
+		}
+		r.PlayerIDs = append(r.PlayerIDs, p)
+	}
+
+	n, err = d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < n; i++ {
+		name, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		typ, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		f := Field{Name: string(name), Type: FieldType(typ), Value: value}
+		if i > 0 && f.Name <= r.Extra[i-1].Name {
+			return nil, xerrors.New("Decode fields aren't canonical")
+		}
+		if err := f.validate(); err != nil {
+			return nil, xerrors.Errorf("Decode %w", err)
+		}
+		r.Extra = append(r.Extra, f)
+	}
+
+	if len(d.b) != 0 {
+		return nil, xerrors.Errorf("Decode %d trailing bytes", len(d.b))
+	}
+
+	return r, nil
+}
+
+// writeUint32 writes a 4 bytes big endian count
+func writeUint32(buf *bytes.Buffer, n uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], n)
+	buf.Write(b[:])
+}
+
+// writeBytes writes a length prefixed value
+func writeBytes(buf *bytes.Buffer, v []byte) error {
+	if len(v) > MAX_FIELD_SIZE {
+		return xerrors.Errorf("Encode value of %d bytes exceeds %d", len(v), MAX_FIELD_SIZE)
+	}
+
+	writeUint32(buf, uint32(len(v)))
+	buf.Write(v)
+	return nil
+}
+
+// decoder reads the encoding produced by Encode
+type decoder struct {
+	b []byte
+}
+
+func (d *decoder) byte() (byte, error) {
+	if len(d.b) < 1 {
+		return 0, xerrors.New("Decode unexpected end of entropy")
+	}
+
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v, nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if len(d.b) < 4 {
+		return 0, xerrors.New("Decode unexpected end of entropy")
+	}
+
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v, nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > MAX_FIELD_SIZE || uint64(n) > uint64(len(d.b)) {
+		return nil, xerrors.New("Decode invalid value length")
+	}
+
+	v := append([]byte(nil), d.b[:n]...)
+	d.b = d.b[n:]
+	return v, nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	cborgen "github.com/whyrusleeping/cbor-gen"
+)
+
+func main() {
+	err := cborgen.WriteMapEncodersToFile("cbor_gen.go", "gamevrf", gamevrf.VRFReceipt{})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
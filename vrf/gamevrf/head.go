@@ -0,0 +1,267 @@
+package gamevrf
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DEFAULT_HEAD_POLL_INTERVAL is the interval of the ChainHead polls of a HeadTracker without a subscription
+	DEFAULT_HEAD_POLL_INTERVAL = FILECOIN_EPOCH_DURATION * time.Second
+	// DEFAULT_HEAD_MAX_AGE is how long a head pushed by a subscription is trusted without a new one, a few epochs
+	// since a head is pushed every epoch but null rounds push none
+	DEFAULT_HEAD_MAX_AGE = 3 * FILECOIN_EPOCH_DURATION * time.Second
+	// headReconnectFactor bounds the delay between two subscription attempts to this many poll intervals
+	headReconnectFactor = 16
+)
+
+// HeadNotifier pushes the changes of the chain head, LotusProvider implements it with the lotus ChainNotify api
+type HeadNotifier interface {
+	// ChainNotify sends the head changes on the returned channel until ctx is done or the subscription is lost
+	ChainNotify(ctx context.Context) (<-chan []filrpc.HeadChange, error)
+}
+
+// ChainNotify implements HeadNotifier over the websocket of the lotus node
+func (p *LotusProvider) ChainNotify(ctx context.Context) (<-chan []filrpc.HeadChange, error) {
+	return p.client.ChainNotify(ctx)
+}
+
+// TrackerOption is a single HeadTracker option.
+type TrackerOption func(t *HeadTracker)
+
+// TrackerMaxAgeOption sets how long a head pushed by the subscription is trusted, DEFAULT_HEAD_MAX_AGE by default
+func TrackerMaxAgeOption(maxAge time.Duration) TrackerOption {
+	return func(t *HeadTracker) {
+		t.maxAge = maxAge
+	}
+}
+
+// HeadTracker keeps the exact chain head of a TipSetProvider. Heads are pushed by a subscription when the provider
+// is a HeadNotifier, the tracker polls ChainHead while there is no subscription and tries to subscribe again with
+// an exponential backoff. HeadTracker is a TipSetProvider whose ChainHead is the tracked head.
+type HeadTracker struct {
+	provider     TipSetProvider
+	notifier     HeadNotifier
+	pollInterval time.Duration
+	maxAge       time.Duration
+
+	lck        sync.Mutex
+	head       *filrpc.TipSet
+	updated    time.Time
+	subscribed bool
+	err        error // last error of the subscription or of a poll
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+var _ TipSetProvider = (*HeadTracker)(nil)
+
+// NewHeadTracker creates a tracker of the head of provider polling every pollInterval when there is no subscription,
+// DEFAULT_HEAD_POLL_INTERVAL if pollInterval is 0. The tracker follows the head once started.
+func NewHeadTracker(provider TipSetProvider, pollInterval time.Duration, options ...TrackerOption) *HeadTracker {
+	if pollInterval <= 0 {
+		pollInterval = DEFAULT_HEAD_POLL_INTERVAL
+	}
+
+	t := &HeadTracker{
+		provider:     provider,
+		pollInterval: pollInterval,
+		maxAge:       DEFAULT_HEAD_MAX_AGE,
+	}
+
+	for _, opt := range options {
+		opt(t)
+	}
+
+	if n, ok := provider.(HeadNotifier); ok {
+		t.notifier = n
+	}
+
+	return t
+}
+
+// Start starts following the chain head in the background until Close, a closed tracker can be started again
+func (t *HeadTracker) Start() {
+	t.lck.Lock()
+	defer t.lck.Unlock()
+
+	if t.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	go t.run(ctx)
+}
+
+// Close stops following the chain head and waits for the subscription to end
+func (t *HeadTracker) Close() {
+	t.lck.Lock()
+	cancel, done := t.cancel, t.done
+	t.lck.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+
+	t.lck.Lock()
+	if t.done == done {
+		t.cancel = nil
+		t.done = nil
+	}
+	t.lck.Unlock()
+}
+
+// Subscribed reports whether heads are pushed by a subscription rather than polled
+func (t *HeadTracker) Subscribed() bool {
+	t.lck.Lock()
+	defer t.lck.Unlock()
+
+	return t.subscribed
+}
+
+// Err returns the last error of the subscription or of a poll, nil once a head is received again
+func (t *HeadTracker) Err() error {
+	t.lck.Lock()
+	defer t.lck.Unlock()
+
+	return t.err
+}
+
+// ChainHead implements TipSetProvider, it returns the tracked head. The head is fetched from the provider
+// when the tracker isn't started, when the subscription pushed no head within the max age, as it can stall
+// without being lost, or when there is no subscription and no head was polled within two intervals.
+func (t *HeadTracker) ChainHead(ctx context.Context) (*filrpc.TipSet, error) {
+	t.lck.Lock()
+	head := t.head
+	age := time.Since(t.updated)
+	fresh := age < 2*t.pollInterval
+	if t.subscribed {
+		fresh = age < t.maxAge
+	}
+	t.lck.Unlock()
+
+	if head != nil && fresh {
+		return head, nil
+	}
+
+	return t.poll(ctx)
+}
+
+// ChainGetTipSetByHeight implements TipSetProvider
+func (t *HeadTracker) ChainGetTipSetByHeight(ctx context.Context, height int64) (*filrpc.TipSet, error) {
+	return t.provider.ChainGetTipSetByHeight(ctx, height)
+}
+
+// run subscribes when it can and polls otherwise until ctx is done
+func (t *HeadTracker) run(ctx context.Context) {
+	defer close(t.done)
+
+	delay := t.pollInterval
+	var next time.Time // of the next subscription attempt
+	for {
+		if t.notifier != nil && !time.Now().Before(next) {
+			if t.follow(ctx) {
+				// the subscription was lost, subscribe again after the next poll
+				delay = t.pollInterval
+			} else {
+				next = time.Now().Add(delay)
+				if delay < headReconnectFactor*t.pollInterval {
+					delay *= 2
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		t.poll(ctx)
+
+		timer := time.NewTimer(t.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// follow applies the head changes of a subscription until it's lost, it returns false if there was none
+func (t *HeadTracker) follow(ctx context.Context) bool {
+	ch, err := t.notifier.ChainNotify(ctx)
+	if err != nil {
+		t.setErr(xerrors.Errorf("HeadTracker subscribe %w", err))
+		return false
+	}
+
+	t.lck.Lock()
+	t.subscribed = true
+	t.lck.Unlock()
+
+	for changes := range ch {
+		var head *filrpc.TipSet
+		for _, c := range changes {
+			if c.Type == filrpc.HCApply || c.Type == filrpc.HCCurrent {
+				head = c.Val
+			}
+		}
+
+		if head == nil {
+			// only reverts, the new head is announced by the next changes
+			continue
+		}
+
+		t.setHead(head)
+	}
+
+	t.lck.Lock()
+	t.subscribed = false
+	t.lck.Unlock()
+
+	if ctx.Err() == nil {
+		t.setErr(xerrors.New("HeadTracker subscription lost"))
+	}
+
+	return true
+}
+
+// poll fetches the head from the provider
+func (t *HeadTracker) poll(ctx context.Context) (*filrpc.TipSet, error) {
+	ts, err := t.provider.ChainHead(ctx)
+	if err != nil {
+		err = xerrors.Errorf("HeadTracker ChainHead %w", err)
+		t.setErr(err)
+		return nil, err
+	}
+
+	t.setHead(ts)
+	return ts, nil
+}
+
+func (t *HeadTracker) setHead(ts *filrpc.TipSet) {
+	t.lck.Lock()
+	defer t.lck.Unlock()
+
+	t.head = ts
+	t.updated = time.Now()
+	t.err = nil
+}
+
+func (t *HeadTracker) setErr(err error) {
+	t.lck.Lock()
+	defer t.lck.Unlock()
+
+	t.err = err
+}
@@ -0,0 +1,331 @@
+// Package keystore stores Filecoin BLS private keys in password encrypted files
+// and converts them from and to the lotus wallet export format
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// KEYSTORE_VERSION is the version of the keystore file format
+	KEYSTORE_VERSION = 1
+	// BLS_PRIVATE_KEY_SIZE is the size of a Filecoin BLS private key in bytes
+	BLS_PRIVATE_KEY_SIZE = 32
+	// SCRYPT_MAX_MEMORY bounds the 128*N*r bytes scrypt allocates, so a crafted file can't exhaust the memory
+	SCRYPT_MAX_MEMORY = 1 << 30
+	// SCRYPT_MAX_P bounds the scrypt parallelization, the derivation time grows linearly with it
+	SCRYPT_MAX_P = 16
+
+	cipherAES256GCM = "aes-256-gcm"
+	kdfScrypt       = "scrypt"
+	aesKeySize      = 32
+	saltSize        = 32
+)
+
+// ScryptParams are the cost parameters of the scrypt key derivation
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// validate checks the parameters are within SCRYPT_MAX_MEMORY and SCRYPT_MAX_P
+func (p ScryptParams) validate() error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 {
+		return xerrors.Errorf("invalid scrypt parameters n %d r %d p %d", p.N, p.R, p.P)
+	}
+
+	if uint64(p.N)*uint64(p.R) > SCRYPT_MAX_MEMORY/128 || p.P > SCRYPT_MAX_P {
+		return xerrors.Errorf("scrypt parameters n %d r %d p %d exceed the maximum of %d bytes and p %d", p.N, p.R, p.P, SCRYPT_MAX_MEMORY, SCRYPT_MAX_P)
+	}
+
+	return nil
+}
+
+// DefaultScryptParams returns the parameters used for new keystore files, deriving a key takes about a second
+func DefaultScryptParams() ScryptParams {
+	return ScryptParams{N: 1 << 18, R: 8, P: 1}
+}
+
+// LightScryptParams returns cheap parameters for tests and low powered devices
+func LightScryptParams() ScryptParams {
+	return ScryptParams{N: 1 << 12, R: 8, P: 6}
+}
+
+// Key is a Filecoin BLS private key in the little endian byte order used by lotus and gamevrf
+type Key struct {
+	Type       string
+	PrivateKey []byte
+}
+
+// NewKey validates a private key of the lotus key type typ, only gamevrf.KTBLS keys are accepted
+func NewKey(typ string, privateKey []byte) (*Key, error) {
+	if typ != gamevrf.KTBLS {
+		return nil, xerrors.Errorf("NewKey unsupported key type %q, only %q keys can generate VRFs", typ, gamevrf.KTBLS)
+	}
+
+	if len(privateKey) != BLS_PRIVATE_KEY_SIZE {
+		return nil, xerrors.Errorf("NewKey invalid private key size %d, expect %d", len(privateKey), BLS_PRIVATE_KEY_SIZE)
+	}
+
+	_, err := gamevrf.FilBlsKey2PublicKey(privateKey)
+	if err != nil {
+		return nil, xerrors.Errorf("NewKey invalid private key: %w", err)
+	}
+
+	return &Key{Type: typ, PrivateKey: append([]byte(nil), privateKey...)}, nil
+}
+
+// GenerateKey generates a new random BLS key
+func GenerateKey() (*Key, error) {
+	priv, _, err := gamevrf.KyberBlsGenPrivateKey()
+	if err != nil {
+		return nil, xerrors.Errorf("GenerateKey failed: %w", err)
+	}
+
+	return NewKey(gamevrf.KTBLS, gamevrf.KyberBlsKey2FilBlsKey(priv))
+}
+
+// ImportLotus parses a key exported by 'lotus wallet export', the hex encoded json of a lotus KeyInfo
+func ImportLotus(exported string) (*Key, error) {
+	b, err := hex.DecodeString(exported)
+	if err != nil {
+		return nil, xerrors.Errorf("ImportLotus hex decode failed: %w", err)
+	}
+
+	var ki gamevrf.KeyInfo
+	err = json.Unmarshal(b, &ki)
+	if err != nil {
+		return nil, xerrors.Errorf("ImportLotus json decode failed: %w", err)
+	}
+
+	return NewKey(ki.Type, ki.PrivateKey)
+}
+
+// ExportLotus encodes the key as 'lotus wallet import' expects it
+func (k *Key) ExportLotus() (string, error) {
+	b, err := json.Marshal(k.KeyInfo())
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// KeyInfo returns the lotus KeyInfo of the key
+func (k *Key) KeyInfo() gamevrf.KeyInfo {
+	return gamevrf.KeyInfo{Type: k.Type, PrivateKey: append([]byte(nil), k.PrivateKey...)}
+}
+
+// PublicKey returns the BLS public key
+func (k *Key) PublicKey() ([]byte, error) {
+	return gamevrf.FilBlsKey2PublicKey(k.PrivateKey)
+}
+
+// Address returns the f3/t3 address of the key
+func (k *Key) Address() (address.Address, error) {
+	pub, err := k.PublicKey()
+	if err != nil {
+		return address.Undef, err
+	}
+
+	return address.NewBLSAddress(pub)
+}
+
+// AddressString formats addr with the prefix of network, address.Mainnet gives f3 and address.Testnet t3
+// addresses regardless of address.CurrentNetwork
+func AddressString(addr address.Address, network address.Network) (string, error) {
+	var prefix string
+	switch network {
+	case address.Mainnet:
+		prefix = address.MainnetPrefix
+	case address.Testnet:
+		prefix = address.TestnetPrefix
+	default:
+		return "", address.ErrUnknownNetwork
+	}
+
+	s := addr.String()
+	if s == address.UndefAddressString {
+		return "", xerrors.New("AddressString undefined address")
+	}
+
+	return prefix + s[1:], nil
+}
+
+// CryptoParams holds the encrypted private key and how to decrypt it
+type CryptoParams struct {
+	Cipher     string       `json:"cipher"`
+	CipherText []byte       `json:"ciphertext"`
+	Nonce      []byte       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+	Salt       []byte       `json:"salt"`
+}
+
+// EncryptedKey is the content of a keystore file, the address is stored in clear so files can be
+// told apart without the password and is authenticated together with the type. Its f/t prefix follows
+// address.CurrentNetwork of the writer and is ignored when decrypting, only the address bytes are authenticated
+type EncryptedKey struct {
+	Version int          `json:"version"`
+	Type    string       `json:"type"`
+	Address string       `json:"address"`
+	Crypto  CryptoParams `json:"crypto"`
+}
+
+// Encrypt encrypts the key with an AES-256-GCM key derived from password by scrypt,
+// params must be within SCRYPT_MAX_MEMORY and SCRYPT_MAX_P
+func Encrypt(key *Key, password []byte, params ScryptParams) (*EncryptedKey, error) {
+	addr, err := key.Address()
+	if err != nil {
+		return nil, xerrors.Errorf("Encrypt invalid key: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	ek := &EncryptedKey{
+		Version: KEYSTORE_VERSION,
+		Type:    key.Type,
+		Address: addr.String(),
+		Crypto: CryptoParams{
+			Cipher:    cipherAES256GCM,
+			KDF:       kdfScrypt,
+			KDFParams: params,
+			Salt:      salt,
+		},
+	}
+
+	aead, err := ek.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	ek.Crypto.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(ek.Crypto.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	ek.Crypto.CipherText = aead.Seal(nil, ek.Crypto.Nonce, key.PrivateKey, ek.additionalData(addr))
+
+	return ek, nil
+}
+
+// Decrypt decrypts the key with password, a wrong password or a tampered file fails authentication
+func Decrypt(ek *EncryptedKey, password []byte) (*Key, error) {
+	if ek.Version != KEYSTORE_VERSION {
+		return nil, xerrors.Errorf("Decrypt unsupported keystore version %d", ek.Version)
+	}
+
+	stored, err := address.NewFromString(ek.Address)
+	if err != nil {
+		return nil, xerrors.Errorf("Decrypt invalid keystore address %q: %w", ek.Address, err)
+	}
+
+	aead, err := ek.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ek.Crypto.Nonce) != aead.NonceSize() {
+		return nil, xerrors.Errorf("Decrypt invalid nonce size %d, expect %d", len(ek.Crypto.Nonce), aead.NonceSize())
+	}
+
+	priv, err := aead.Open(nil, ek.Crypto.Nonce, ek.Crypto.CipherText, ek.additionalData(stored))
+	if err != nil {
+		return nil, xerrors.New("Decrypt wrong password or corrupted keystore")
+	}
+
+	key, err := NewKey(ek.Type, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := key.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(addr.Bytes(), stored.Bytes()) {
+		return nil, xerrors.Errorf("Decrypt key address %s doesn't match keystore address %s", addr, ek.Address)
+	}
+
+	return key, nil
+}
+
+// aead derives the AES-256-GCM cipher from password
+func (ek *EncryptedKey) aead(password []byte) (cipher.AEAD, error) {
+	if ek.Crypto.Cipher != cipherAES256GCM || ek.Crypto.KDF != kdfScrypt {
+		return nil, xerrors.Errorf("unsupported cipher %q or kdf %q", ek.Crypto.Cipher, ek.Crypto.KDF)
+	}
+
+	p := ek.Crypto.KDFParams
+	err := p.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	dk, err := scrypt.Key(password, ek.Crypto.Salt, p.N, p.R, p.P, aesKeySize)
+	if err != nil {
+		return nil, xerrors.Errorf("scrypt failed: %w", err)
+	}
+
+	block, err := aes.NewCipher(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the clear fields of the file to the ciphertext, the address by its network independent bytes
+func (ek *EncryptedKey) additionalData(addr address.Address) []byte {
+	return []byte(ek.Type + ":" + hex.EncodeToString(addr.Bytes()))
+}
+
+// WriteFile encrypts the key and writes it to path readable only by the owner
+func WriteFile(path string, key *Key, password []byte, params ScryptParams) error {
+	ek, err := Encrypt(key, password, params)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(ek, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0600)
+}
+
+// ReadFile reads and decrypts the keystore file at path
+func ReadFile(path string, password []byte) (*Key, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ek EncryptedKey
+	err = json.Unmarshal(b, &ek)
+	if err != nil {
+		return nil, xerrors.Errorf("ReadFile %s invalid keystore: %w", path, err)
+	}
+
+	return Decrypt(&ek, password)
+}
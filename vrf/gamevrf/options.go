@@ -0,0 +1,55 @@
+package gamevrf
+
+// Option is a single GameVRF option.
+type Option func(g *GameVRF)
+
+// TicketVerifyOption makes GameVRF verify the min ticket of every tipset it uses instead of trusting the provider,
+// worker keys are resolved by workers and smokeHeight is the network's smoke upgrade height. workers must not trust
+// the provider: pinned WorkerKeys or a LotusWorkerResolver over another, trusted node.
+func TicketVerifyOption(workers WorkerKeyResolver, smokeHeight uint64) Option {
+	return func(g *GameVRF) {
+		g.workers = workers
+		g.smokeHeight = smokeHeight
+	}
+}
+
+// TipSetCacheOption makes GameVRF keep the tipsets of the last size heights in memory and share concurrent fetches
+// of the same height, finalized tipsets are also written to store if it isn't nil
+func TipSetCacheOption(size int, store TipSetStore) Option {
+	return func(g *GameVRF) {
+		g.cacheSize = size
+		g.cacheStore = store
+	}
+}
+
+// PolicyOption replaces the DefaultPolicy used to choose VRF bases, see ECFinalityPolicy for bases that can't be reorged
+func PolicyOption(policy Policy) Option {
+	return func(g *GameVRF) {
+		g.policy = policy
+	}
+}
+
+// TagRegistryOption makes GameVRF reject tags not registered in registry on generation and on verification,
+// games sharing a key use a registry each to keep their VRFs apart
+func TagRegistryOption(registry *TagRegistry) Option {
+	return func(g *GameVRF) {
+		g.tags = registry
+	}
+}
+
+// DrandBaseOption makes GameVRF generate VRFs over the latest drand beacon of source instead of a tipset min ticket,
+// beacons are verified against info. VRFs over either kind of base are verified.
+func DrandBaseOption(info *DrandChainInfo, source DrandSource) Option {
+	return func(g *GameVRF) {
+		g.drandInfo = info
+		g.drandSource = source
+	}
+}
+
+// HeadTrackerOption makes GameVRF take the chain head from tracker, the exact head pushed by a subscription or polled,
+// instead of the cached head plus the epochs elapsed since it was fetched. The tracker is started and closed by the caller.
+func HeadTrackerOption(tracker *HeadTracker) Option {
+	return func(g *GameVRF) {
+		g.head = tracker
+	}
+}
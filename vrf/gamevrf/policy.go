@@ -0,0 +1,137 @@
+package gamevrf
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"golang.org/x/xerrors"
+)
+
+// NullRoundSearch is the direction searched for a tipset when the requested height is a null round
+type NullRoundSearch int
+
+const (
+	// NullRoundSearchBackward uses the closest tipset below the null round, as lotus ChainGetTipSetByHeight does
+	NullRoundSearchBackward NullRoundSearch = iota
+	// NullRoundSearchForward uses the closest tipset above the null round
+	NullRoundSearchForward
+	// NullRoundSearchNone fails if the requested height is a null round
+	NullRoundSearchNone
+)
+
+// String returns the name of the search direction
+func (s NullRoundSearch) String() string {
+	switch s {
+	case NullRoundSearchBackward:
+		return "backward"
+	case NullRoundSearchForward:
+		return "forward"
+	case NullRoundSearchNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+// Policy decides which tipset GameVRF draws its VRF base from, trading latency against reorg safety
+type Policy struct {
+	Lookback         uint64          // epochs between the game epoch and the requested base height
+	MinConfirmations uint64          // epochs the base tipset must be below the chain head, 0 to skip the check
+	NullRoundSearch  NullRoundSearch // direction searched when the requested height is a null round
+	NullRoundLimit   uint64          // maximum number of null rounds crossed by the search
+}
+
+// DefaultPolicy returns the policy GameVRF uses unless PolicyOption is given
+func DefaultPolicy() Policy {
+	return Policy{
+		Lookback:        GAME_CHAIN_EPOCH_LOOKBACK,
+		NullRoundSearch: NullRoundSearchBackward,
+		NullRoundLimit:  GAME_CHAIN_EPOCH_LOOKBACK,
+	}
+}
+
+// ECFinalityPolicy returns a policy whose VRF bases are final under expected consensus, they can't be reorged
+// but are FILECOIN_CHAIN_FINALITY epochs (7.5 hours) old
+func ECFinalityPolicy() Policy {
+	return Policy{
+		Lookback:         FILECOIN_CHAIN_FINALITY,
+		MinConfirmations: FILECOIN_CHAIN_FINALITY,
+		NullRoundSearch:  NullRoundSearchBackward,
+		NullRoundLimit:   GAME_CHAIN_EPOCH_LOOKBACK,
+	}
+}
+
+// VRFMeta describes how the VRF base of a generated VRF was chosen
+type VRFMeta struct {
+	BaseKind        BaseKind
+	GameEpoch       uint64 // estimated current epoch the lookback was counted from
+	RequestedHeight uint64 // game epoch minus the policy lookback, the drand round for BaseKind_Drand
+	BaseHeight      uint64 // height of the tipset or drand round actually used, equal to VRFOut.Height
+	HeadHeight      uint64 // chain head the confirmations were counted against, 0 if not checked
+	Confirmations   uint64 // epochs between BaseHeight and HeadHeight, 0 if not checked
+	NullRounds      uint64 // null rounds crossed between RequestedHeight and BaseHeight
+}
+
+// findTipSet returns the non-empty tipset for height following the null round policy
+func (g *GameVRF) findTipSet(ctx context.Context, height uint64) (*filrpc.TipSet, error) {
+	switch g.policy.NullRoundSearch {
+	case NullRoundSearchForward:
+		for h := height; h <= height+g.policy.NullRoundLimit; h++ {
+			tps, err := g.provider.ChainGetTipSetByHeight(ctx, int64(h))
+			if err != nil {
+				return nil, err
+			}
+
+			if tps.Height() == h && len(tps.Blocks()) > 0 {
+				return tps, nil
+			}
+		}
+
+		return nil, xerrors.Errorf("no tipset within %d null rounds above height %d", g.policy.NullRoundLimit, height)
+	case NullRoundSearchNone, NullRoundSearchBackward:
+		tps, err := g.provider.ChainGetTipSetByHeight(ctx, int64(height))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(tps.Blocks()) == 0 || tps.Height() > height {
+			return nil, xerrors.Errorf("provider returned an invalid tipset for height %d", height)
+		}
+
+		nulls := height - tps.Height()
+		if nulls > 0 && g.policy.NullRoundSearch == NullRoundSearchNone {
+			return nil, xerrors.Errorf("height %d is a null round", height)
+		}
+
+		if nulls > g.policy.NullRoundLimit {
+			return nil, xerrors.Errorf("no tipset within %d null rounds below height %d", g.policy.NullRoundLimit, height)
+		}
+
+		return tps, nil
+	default:
+		return nil, xerrors.Errorf("unknown null round search %d", g.policy.NullRoundSearch)
+	}
+}
+
+// checkConfirmations makes sure the tipset at height is buried deep enough under the chain head
+func (g *GameVRF) checkConfirmations(ctx context.Context, height uint64) (head uint64, confirmations uint64, err error) {
+	if g.policy.MinConfirmations == 0 {
+		return 0, 0, nil
+	}
+
+	head, err = g.getChainHead(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if head < height+g.policy.MinConfirmations {
+		var have uint64
+		if head > height {
+			have = head - height
+		}
+		return 0, 0, xerrors.Errorf("tipset at height %d has %d confirmations, %d required", height, have, g.policy.MinConfirmations)
+	}
+
+	return head, head - height, nil
+}
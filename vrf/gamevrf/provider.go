@@ -0,0 +1,203 @@
+package gamevrf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"golang.org/x/xerrors"
+)
+
+// TIPSET_ARCHIVE_VERSION is the version of the tipset archive file format
+const TIPSET_ARCHIVE_VERSION = 1
+
+// TipSetProvider provides the tipsets GameVRF draws its VRF base from
+type TipSetProvider interface {
+	// ChainHead returns the current head of the chain
+	ChainHead(ctx context.Context) (*filrpc.TipSet, error)
+	// ChainGetTipSetByHeight returns the tipset at height, or the closest tipset below it if height is a null round
+	ChainGetTipSetByHeight(ctx context.Context, height int64) (*filrpc.TipSet, error)
+}
+
+// LotusProvider is a TipSetProvider backed by the lotus json rpc api
+type LotusProvider struct {
+	client *filrpc.Client
+}
+
+// NewLotusProvider creates a TipSetProvider that talks to the lotus node configured by options
+func NewLotusProvider(options ...filrpc.Option) *LotusProvider {
+	return &LotusProvider{
+		client: filrpc.New(options...),
+	}
+}
+
+// ChainHead implements TipSetProvider
+func (p *LotusProvider) ChainHead(ctx context.Context) (*filrpc.TipSet, error) {
+	return p.client.ChainHeadContext(ctx)
+}
+
+// ChainGetTipSetByHeight implements TipSetProvider
+func (p *LotusProvider) ChainGetTipSetByHeight(ctx context.Context, height int64) (*filrpc.TipSet, error) {
+	return p.client.ChainGetTipSetByHeightContext(ctx, height)
+}
+
+// MemoryProvider is a TipSetProvider serving a fixed set of tipsets from memory, the highest tipset is the chain head
+type MemoryProvider struct {
+	lck     sync.RWMutex
+	tipsets map[uint64]*filrpc.TipSet
+	heights []uint64 // sorted heights of tipsets
+}
+
+// NewMemoryProvider creates a MemoryProvider holding tipsets
+func NewMemoryProvider(tipsets ...*filrpc.TipSet) *MemoryProvider {
+	p := &MemoryProvider{
+		tipsets: make(map[uint64]*filrpc.TipSet),
+	}
+
+	for _, ts := range tipsets {
+		p.Add(ts)
+	}
+
+	return p
+}
+
+// Add adds a tipset to the provider, replacing any tipset at the same height
+func (p *MemoryProvider) Add(ts *filrpc.TipSet) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	if _, ok := p.tipsets[ts.Height()]; !ok {
+		p.heights = append(p.heights, ts.Height())
+		sort.Slice(p.heights, func(i, j int) bool { return p.heights[i] < p.heights[j] })
+	}
+
+	p.tipsets[ts.Height()] = ts
+}
+
+// TipSets returns all tipsets held by the provider ordered by height
+func (p *MemoryProvider) TipSets() []*filrpc.TipSet {
+	p.lck.RLock()
+	defer p.lck.RUnlock()
+
+	tipsets := make([]*filrpc.TipSet, 0, len(p.heights))
+	for _, h := range p.heights {
+		tipsets = append(tipsets, p.tipsets[h])
+	}
+
+	return tipsets
+}
+
+// ChainHead implements TipSetProvider
+func (p *MemoryProvider) ChainHead(ctx context.Context) (*filrpc.TipSet, error) {
+	p.lck.RLock()
+	defer p.lck.RUnlock()
+
+	if len(p.heights) == 0 {
+		return nil, xerrors.Errorf("MemoryProvider has no tipset")
+	}
+
+	return p.tipsets[p.heights[len(p.heights)-1]], nil
+}
+
+// ChainGetTipSetByHeight implements TipSetProvider, heights between two known tipsets are treated as null rounds
+func (p *MemoryProvider) ChainGetTipSetByHeight(ctx context.Context, height int64) (*filrpc.TipSet, error) {
+	p.lck.RLock()
+	defer p.lck.RUnlock()
+
+	if height < 0 {
+		return nil, xerrors.Errorf("MemoryProvider invalid height: %d", height)
+	}
+
+	if len(p.heights) == 0 || uint64(height) > p.heights[len(p.heights)-1] {
+		return nil, xerrors.Errorf("MemoryProvider height %d is in the future", height)
+	}
+
+	// index of the first tipset above height
+	i := sort.Search(len(p.heights), func(i int) bool { return p.heights[i] > uint64(height) })
+	if i == 0 {
+		return nil, xerrors.Errorf("MemoryProvider has no tipset at or below height %d", height)
+	}
+
+	return p.tipsets[p.heights[i-1]], nil
+}
+
+// TipSetArchive is the file format of a recorded set of tipsets
+type TipSetArchive struct {
+	Version int
+	TipSets []*filrpc.TipSet
+	Workers []MinerWorker `json:",omitempty"` // worker keys of the recorded miners, used to verify tickets
+}
+
+// FileProvider is a TipSetProvider serving the tipsets recorded in an archive file,
+// it is also a WorkerKeyResolver over the recorded worker keys
+type FileProvider struct {
+	*MemoryProvider
+	WorkerKeys
+	path string
+}
+
+// NewFileProvider loads the tipset archive at path
+func NewFileProvider(path string) (*FileProvider, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("NewFileProvider read archive failed: %w", err)
+	}
+
+	var archive TipSetArchive
+	err = json.Unmarshal(b, &archive)
+	if err != nil {
+		return nil, xerrors.Errorf("NewFileProvider decode archive failed: %w", err)
+	}
+
+	if archive.Version != TIPSET_ARCHIVE_VERSION {
+		return nil, xerrors.Errorf("NewFileProvider unsupported archive version: %d", archive.Version)
+	}
+
+	return &FileProvider{
+		MemoryProvider: NewMemoryProvider(archive.TipSets...),
+		WorkerKeys:     NewWorkerKeys(archive.Workers),
+		path:           path,
+	}, nil
+}
+
+// Save writes the tipsets and worker keys currently held by the provider back to its archive file
+func (p *FileProvider) Save() error {
+	var workers []MinerWorker
+	for miner, worker := range p.WorkerKeys {
+		workers = append(workers, MinerWorker{Miner: miner, Worker: worker})
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].Miner.String() < workers[j].Miner.String() })
+
+	return WriteArchive(p.path, &TipSetArchive{
+		Version: TIPSET_ARCHIVE_VERSION,
+		TipSets: p.TipSets(),
+		Workers: workers,
+	})
+}
+
+// WriteTipSetArchive records tipsets to an archive file at path which can be loaded by NewFileProvider
+func WriteTipSetArchive(path string, tipsets []*filrpc.TipSet) error {
+	return WriteArchive(path, &TipSetArchive{
+		Version: TIPSET_ARCHIVE_VERSION,
+		TipSets: tipsets,
+	})
+}
+
+// WriteArchive writes archive to a file at path which can be loaded by NewFileProvider
+func WriteArchive(path string, archive *TipSetArchive) error {
+	b, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("WriteArchive encode archive failed: %w", err)
+	}
+
+	err = os.WriteFile(path, b, 0644)
+	if err != nil {
+		return xerrors.Errorf("WriteArchive write archive failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,264 @@
+package gamevrf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"golang.org/x/xerrors"
+)
+
+// QuorumEndpoint is a named TipSetProvider taking part in a quorum
+type QuorumEndpoint struct {
+	Name     string
+	Provider TipSetProvider
+}
+
+// QuorumReport describes how the endpoints of a quorum answered a request
+type QuorumReport struct {
+	Method    string
+	Height    int64
+	Agreed    []string         // endpoints returning the accepted tipset
+	Disagreed []string         // endpoints returning a different min ticket or height
+	Failed    map[string]error // endpoints failing to answer
+}
+
+// QuorumProviderOption is a single QuorumProvider option.
+type QuorumProviderOption func(p *QuorumProvider)
+
+// QuorumReporterOption sets a function called with the report of every request some endpoint didn't agree on
+func QuorumReporterOption(reporter func(report *QuorumReport)) QuorumProviderOption {
+	return func(p *QuorumProvider) {
+		p.reporter = reporter
+	}
+}
+
+// QuorumProvider is a TipSetProvider querying several endpoints and requiring threshold of them
+// to agree on the min ticket of a tipset, so a single compromised endpoint can't fake the VRF base
+type QuorumProvider struct {
+	endpoints []QuorumEndpoint
+	threshold int
+	reporter  func(report *QuorumReport)
+
+	lck           sync.Mutex
+	disagreements map[string]uint64
+}
+
+// NewQuorumProvider creates a QuorumProvider requiring threshold of endpoints to agree
+func NewQuorumProvider(threshold int, endpoints []QuorumEndpoint, options ...QuorumProviderOption) (*QuorumProvider, error) {
+	if threshold <= 0 || threshold > len(endpoints) {
+		return nil, xerrors.Errorf("NewQuorumProvider invalid threshold %d of %d endpoints", threshold, len(endpoints))
+	}
+
+	names := make(map[string]bool)
+	for _, ep := range endpoints {
+		if names[ep.Name] {
+			return nil, xerrors.Errorf("NewQuorumProvider duplicated endpoint name %s", ep.Name)
+		}
+		names[ep.Name] = true
+	}
+
+	p := &QuorumProvider{
+		endpoints:     endpoints,
+		threshold:     threshold,
+		disagreements: make(map[string]uint64),
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// NewLotusQuorumProvider creates a QuorumProvider over lotus nodes, options apply to every node and the node url is the endpoint name
+func NewLotusQuorumProvider(threshold int, nodeURLs []string, options ...filrpc.Option) (*QuorumProvider, error) {
+	endpoints := make([]QuorumEndpoint, 0, len(nodeURLs))
+	for _, url := range nodeURLs {
+		opts := append(append([]filrpc.Option{}, options...), filrpc.NodeURLOption(url))
+		endpoints = append(endpoints, QuorumEndpoint{
+			Name:     url,
+			Provider: NewLotusProvider(opts...),
+		})
+	}
+
+	return NewQuorumProvider(threshold, endpoints)
+}
+
+// Disagreements returns how many times each endpoint failed or disagreed with the quorum
+func (p *QuorumProvider) Disagreements() map[string]uint64 {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	counts := make(map[string]uint64, len(p.disagreements))
+	for name, n := range p.disagreements {
+		counts[name] = n
+	}
+
+	return counts
+}
+
+type quorumAnswer struct {
+	name string
+	ts   *filrpc.TipSet
+	err  error
+}
+
+// queryAll runs fn against all endpoints concurrently
+func (p *QuorumProvider) queryAll(ctx context.Context, fn func(ctx context.Context, provider TipSetProvider) (*filrpc.TipSet, error)) []quorumAnswer {
+	answers := make([]quorumAnswer, len(p.endpoints))
+
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, ep QuorumEndpoint) {
+			defer wg.Done()
+
+			ts, err := fn(ctx, ep.Provider)
+			answers[i] = quorumAnswer{name: ep.Name, ts: ts, err: err}
+		}(i, ep)
+	}
+	wg.Wait()
+
+	return answers
+}
+
+// ChainHead implements TipSetProvider, the head is the highest height reached by threshold endpoints
+// and its tipset must be agreed on like ChainGetTipSetByHeight
+func (p *QuorumProvider) ChainHead(ctx context.Context) (*filrpc.TipSet, error) {
+	answers := p.queryAll(ctx, func(ctx context.Context, provider TipSetProvider) (*filrpc.TipSet, error) {
+		return provider.ChainHead(ctx)
+	})
+
+	var heights []uint64
+	var errs []string
+	for _, a := range answers {
+		if a.err != nil {
+			errs = append(errs, a.name+": "+a.err.Error())
+			continue
+		}
+		heights = append(heights, a.ts.Height())
+	}
+
+	if len(heights) < p.threshold {
+		return nil, xerrors.Errorf("QuorumProvider ChainHead only %d of %d endpoints answered: %s", len(heights), p.threshold, strings.Join(errs, "; "))
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+
+	return p.ChainGetTipSetByHeight(ctx, int64(heights[p.threshold-1]))
+}
+
+// ChainGetTipSetByHeight implements TipSetProvider
+func (p *QuorumProvider) ChainGetTipSetByHeight(ctx context.Context, height int64) (*filrpc.TipSet, error) {
+	answers := p.queryAll(ctx, func(ctx context.Context, provider TipSetProvider) (*filrpc.TipSet, error) {
+		return provider.ChainGetTipSetByHeight(ctx, height)
+	})
+
+	report := &QuorumReport{
+		Method: "ChainGetTipSetByHeight",
+		Height: height,
+		Failed: make(map[string]error),
+	}
+
+	// group the answers by tipset height, min ticket and tipset key, answers with the same ticket
+	// but other blocks disagree
+	groups := make(map[string][]quorumAnswer)
+	for _, a := range answers {
+		if a.err == nil && len(a.ts.Blocks()) == 0 {
+			a.err = xerrors.Errorf("empty tipset")
+		}
+
+		if a.err != nil {
+			report.Failed[a.name] = a.err
+			continue
+		}
+
+		key := quorumKey(a.ts)
+		groups[key] = append(groups[key], a)
+	}
+
+	// walk the groups in key order so ties and reports don't depend on map iteration
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var agreedKey string
+	var agreed []quorumAnswer
+	quorums := 0
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) >= p.threshold {
+			quorums++
+		}
+		if len(group) > len(agreed) {
+			agreedKey = key
+			agreed = group
+		}
+	}
+	conflict := quorums > 1
+
+	for _, a := range agreed {
+		report.Agreed = append(report.Agreed, a.name)
+	}
+	sort.Strings(report.Agreed)
+	for _, key := range keys {
+		if key == agreedKey {
+			continue
+		}
+		for _, a := range groups[key] {
+			report.Disagreed = append(report.Disagreed, a.name)
+		}
+	}
+	sort.Strings(report.Disagreed)
+
+	p.record(report)
+
+	if conflict {
+		return nil, xerrors.Errorf("QuorumProvider conflicting quorums at height %d, disagreed: %v", height, report.Disagreed)
+	}
+
+	if len(agreed) < p.threshold {
+		return nil, xerrors.Errorf("QuorumProvider no quorum at height %d: %d of %d endpoints agree, disagreed: %v, failed: %v",
+			height, len(agreed), p.threshold, report.Disagreed, report.Failed)
+	}
+
+	return agreed[0].ts, nil
+}
+
+// quorumKey identifies a tipset by height, min ticket and sorted block cids
+func quorumKey(ts *filrpc.TipSet) string {
+	cids := make([]string, 0, len(ts.Cids()))
+	for _, c := range ts.Cids() {
+		cids = append(cids, c.String())
+	}
+	sort.Strings(cids)
+
+	return fmt.Sprintf("%d@%x@%s", ts.Height(), ts.MinTicket().VRFProof, strings.Join(cids, ","))
+}
+
+// record counts the endpoints not agreeing with the quorum and reports them
+func (p *QuorumProvider) record(report *QuorumReport) {
+	if len(report.Disagreed) == 0 && len(report.Failed) == 0 {
+		return
+	}
+
+	p.lck.Lock()
+	for _, name := range report.Disagreed {
+		p.disagreements[name]++
+	}
+	for name := range report.Failed {
+		p.disagreements[name]++
+	}
+	p.lck.Unlock()
+
+	if p.reporter != nil {
+		p.reporter(report)
+	}
+}
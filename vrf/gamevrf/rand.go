@@ -32,6 +32,12 @@ func drawRandomness(rbase []byte, pers DomainSeparationTag, height uint64, entro
 	return h.Sum(nil), nil
 }
 
+// DrawRandomness returns the message signed by a VRF: blake2b-256 over the big endian int64 tag,
+// blake2b-256 of the VRF base, the big endian height and the entropy
+func DrawRandomness(rbase []byte, pers DomainSeparationTag, height uint64, entropy []byte) ([]byte, error) {
+	return drawRandomness(rbase, pers, height, entropy)
+}
+
 // VerifyVRF verifies a VRF proof given the public key, domain separation tag, VRF base, entropy, and the VRF output
 func VerifyVRF(pubkey []byte,
 	pers DomainSeparationTag, rbase []byte, entropy []byte, vrf *VRFOut) error {
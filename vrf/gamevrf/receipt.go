@@ -0,0 +1,263 @@
+package gamevrf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/minio/blake2b-simd"
+	"golang.org/x/xerrors"
+)
+
+// VRF_RECEIPT_VERSION is the current version of the VRFReceipt format, version 2 added BaseKind and PrevRBase.
+// Receipts of version 1 are min ticket receipts and still verify.
+const VRF_RECEIPT_VERSION = 2
+
+// VRFReceipt bundles everything needed to verify a VRF output without talking to a lotus node
+type VRFReceipt struct {
+	Version     uint64
+	Pers        DomainSeparationTag
+	Height      uint64          // height of the tipset the VRF base was taken from, the drand round for BaseKind_Drand
+	TipSetCids  []cid.Cid       // cids of the tipset blocks, empty if unknown
+	RBase       []byte          // min ticket VRFProof of the tipset, the beacon signature for BaseKind_Drand
+	Entropy     []byte          // entropy of the VRF, empty if only EntropyHash is given
+	EntropyHash []byte          // blake2b-256 of the entropy, empty if Entropy is given
+	Signer      address.Address // BLS address of the VRF signer
+	Proof       []byte
+	BaseKind    BaseKind
+	PrevRBase   []byte // previous beacon signature of chained drand schemes
+}
+
+// NewVRFReceipt creates a receipt for vrf generated over the min ticket of ts, the entropy is embedded in the receipt
+func NewVRFReceipt(pers DomainSeparationTag, ts *filrpc.TipSet, signer address.Address, entropy []byte, vrf *VRFOut) (*VRFReceipt, error) {
+	if ts.Height() != vrf.Height {
+		return nil, xerrors.Errorf("NewVRFReceipt tipset height %d != %d(vrf)", ts.Height(), vrf.Height)
+	}
+
+	if len(ts.Blocks()) == 0 {
+		return nil, xerrors.Errorf("NewVRFReceipt no block in tipset(height:%d)", ts.Height())
+	}
+
+	if signer.Protocol() != address.BLS {
+		return nil, xerrors.Errorf("NewVRFReceipt signer %s is not a BLS address", signer)
+	}
+
+	return &VRFReceipt{
+		Version:    VRF_RECEIPT_VERSION,
+		Pers:       pers,
+		Height:     vrf.Height,
+		TipSetCids: ts.Cids(),
+		RBase:      ts.MinTicket().VRFProof,
+		Entropy:    entropy,
+		Signer:     signer,
+		Proof:      vrf.Proof,
+	}, nil
+}
+
+// NewDrandVRFReceipt creates a receipt for vrf generated over the drand beacon b, the entropy is embedded in the receipt
+func NewDrandVRFReceipt(pers DomainSeparationTag, b *DrandBeacon, signer address.Address, entropy []byte, vrf *VRFOut) (*VRFReceipt, error) {
+	if vrf.BaseKind != BaseKind_Drand || b.Round != vrf.Height {
+		return nil, xerrors.Errorf("NewDrandVRFReceipt vrf wasn't generated over drand round %d", b.Round)
+	}
+
+	if signer.Protocol() != address.BLS {
+		return nil, xerrors.Errorf("NewDrandVRFReceipt signer %s is not a BLS address", signer)
+	}
+
+	return &VRFReceipt{
+		Version:   VRF_RECEIPT_VERSION,
+		Pers:      pers,
+		Height:    vrf.Height,
+		RBase:     b.Signature,
+		Entropy:   entropy,
+		Signer:    signer,
+		Proof:     vrf.Proof,
+		BaseKind:  BaseKind_Drand,
+		PrevRBase: b.PreviousSignature,
+	}, nil
+}
+
+// HashEntropy replaces the embedded entropy with its hash, the entropy then has to be handed to VerifyReceipt separately
+func (r *VRFReceipt) HashEntropy() {
+	if len(r.Entropy) == 0 {
+		return
+	}
+
+	h := blake2b.Sum256(r.Entropy)
+	r.EntropyHash = h[:]
+	r.Entropy = nil
+}
+
+// VRFOut returns the VRF output the receipt is about
+func (r *VRFReceipt) VRFOut() *VRFOut {
+	return &VRFOut{
+		Height:   r.Height,
+		Proof:    r.Proof,
+		BaseKind: r.BaseKind,
+	}
+}
+
+// MarshalReceiptJSON encodes a receipt to json
+func MarshalReceiptJSON(r *VRFReceipt) ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// UnmarshalReceiptJSON decodes a json receipt
+func UnmarshalReceiptJSON(b []byte) (*VRFReceipt, error) {
+	var r VRFReceipt
+	err := json.Unmarshal(b, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// UnmarshalReceiptCBOR decodes a cbor receipt
+func UnmarshalReceiptCBOR(b []byte) (*VRFReceipt, error) {
+	var r VRFReceipt
+	err := r.UnmarshalCBOR(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// receiptEntropy returns the entropy to verify the receipt with, checking it against the receipt's entropy hash if any
+func receiptEntropy(r *VRFReceipt, entropy []byte) ([]byte, error) {
+	if len(r.Entropy) > 0 {
+		if entropy != nil && !bytes.Equal(entropy, r.Entropy) {
+			return nil, xerrors.Errorf("entropy doesn't match the one in receipt")
+		}
+
+		return r.Entropy, nil
+	}
+
+	if len(r.EntropyHash) == 0 {
+		// empty entropy is valid input for drawRandomness
+		return entropy, nil
+	}
+
+	h := blake2b.Sum256(entropy)
+	if !bytes.Equal(h[:], r.EntropyHash) {
+		return nil, xerrors.Errorf("entropy doesn't match the receipt entropy hash")
+	}
+
+	return entropy, nil
+}
+
+// VerifyReceipt verifies a receipt without any rpc call. entropy may be nil if it is embedded in the receipt,
+// otherwise it must hash to the receipt's EntropyHash
+func VerifyReceipt(r *VRFReceipt, entropy []byte) error {
+	if r.Version == 0 || r.Version > VRF_RECEIPT_VERSION {
+		return xerrors.Errorf("VerifyReceipt unsupported receipt version: %d", r.Version)
+	}
+
+	if r.Version == 1 && (r.BaseKind != BaseKind_MinTicket || len(r.PrevRBase) != 0) {
+		return xerrors.Errorf("VerifyReceipt receipt version 1 has no base kind")
+	}
+
+	if r.Signer.Protocol() != address.BLS {
+		return xerrors.Errorf("VerifyReceipt signer %s is not a BLS address", r.Signer)
+	}
+
+	if len(r.RBase) == 0 {
+		return xerrors.Errorf("VerifyReceipt receipt has no rbase")
+	}
+
+	entropy, err := receiptEntropy(r, entropy)
+	if err != nil {
+		return xerrors.Errorf("VerifyReceipt %w", err)
+	}
+
+	return VerifyVRF(r.Signer.Payload(), r.Pers, r.RBase, entropy, r.VRFOut())
+}
+
+// VerifyDrandReceipt verifies a receipt over a drand base including the beacon, drand beacons verify
+// against the chain info so no rpc call is needed
+func VerifyDrandReceipt(info *DrandChainInfo, r *VRFReceipt, entropy []byte) error {
+	if r.BaseKind != BaseKind_Drand {
+		return xerrors.Errorf("VerifyDrandReceipt receipt base kind is %s", r.BaseKind)
+	}
+
+	err := VerifyReceipt(r, entropy)
+	if err != nil {
+		return err
+	}
+
+	return info.VerifyBeacon(&DrandBeacon{Round: r.Height, Signature: r.RBase, PreviousSignature: r.PrevRBase})
+}
+
+// VerifyReceiptOnline verifies a receipt and confirms its tipset data against the chain served by provider,
+// receipts over a drand base are verified by VerifyDrandReceipt
+func VerifyReceiptOnline(ctx context.Context, provider TipSetProvider, r *VRFReceipt, entropy []byte) error {
+	if r.BaseKind != BaseKind_MinTicket {
+		return xerrors.Errorf("VerifyReceiptOnline receipt base kind is %s, use VerifyDrandReceipt", r.BaseKind)
+	}
+
+	err := VerifyReceipt(r, entropy)
+	if err != nil {
+		return err
+	}
+
+	ts, err := provider.ChainGetTipSetByHeight(ctx, int64(r.Height))
+	if err != nil {
+		return xerrors.Errorf("VerifyReceiptOnline get tipset failed: %w", err)
+	}
+
+	if ts.Height() != r.Height {
+		return xerrors.Errorf("VerifyReceiptOnline height %d is a null round on chain", r.Height)
+	}
+
+	if !bytes.Equal(ts.MinTicket().VRFProof, r.RBase) {
+		return xerrors.Errorf("VerifyReceiptOnline rbase doesn't match the min ticket at height %d", r.Height)
+	}
+
+	if len(r.TipSetCids) > 0 && len(ts.Cids()) > 0 {
+		if len(r.TipSetCids) != len(ts.Cids()) {
+			return xerrors.Errorf("VerifyReceiptOnline tipset cids don't match the chain at height %d", r.Height)
+		}
+
+		for i, c := range ts.Cids() {
+			if !c.Equals(r.TipSetCids[i]) {
+				return xerrors.Errorf("VerifyReceiptOnline tipset cids don't match the chain at height %d", r.Height)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Receipt fetches the tipset vrf was generated over and creates a receipt for it
+func (g *GameVRF) Receipt(pers DomainSeparationTag, signer address.Address, entropy []byte, vrf *VRFOut) (*VRFReceipt, error) {
+	return g.ReceiptContext(context.Background(), pers, signer, entropy, vrf)
+}
+
+// ReceiptContext is like Receipt but the chain request is canceled with ctx
+func (g *GameVRF) ReceiptContext(ctx context.Context, pers DomainSeparationTag, signer address.Address, entropy []byte, vrf *VRFOut) (*VRFReceipt, error) {
+	err := g.checkTag(pers)
+	if err != nil {
+		return nil, xerrors.Errorf("ReceiptContext %w", err)
+	}
+
+	if vrf.BaseKind == BaseKind_Drand {
+		b, err := g.drandBase(ctx, vrf.Height)
+		if err != nil {
+			return nil, xerrors.Errorf("ReceiptContext drand base failed: %w", err)
+		}
+
+		return NewDrandVRFReceipt(pers, b, signer, entropy, vrf)
+	}
+
+	tps, err := g.getTipsetByHeight(ctx, vrf.Height)
+	if err != nil {
+		return nil, xerrors.Errorf("ReceiptContext getTipsetByHeight failed: %w", err)
+	}
+
+	return NewVRFReceipt(pers, tps, signer, entropy, vrf)
+}
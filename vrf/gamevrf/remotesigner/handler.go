@@ -0,0 +1,120 @@
+package remotesigner
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+)
+
+// maxRequestSize bounds the body of a sign request
+const maxRequestSize = 1 << 16
+
+// randomnessSize is the size of the randomness drawn from the VRF inputs, the only messages the handler signs
+const randomnessSize = 32
+
+// Handler serves the signing daemon side of the protocol with a gamevrf.Signer, usually a
+// gamevrf.LocalSigner, it only signs the randomness it draws from the requested VRF inputs. It can be mounted in a daemon or used as a mock server in tests
+type Handler struct {
+	signer gamevrf.Signer
+	token  string
+	mux    *http.ServeMux
+}
+
+// NewHandler creates a handler signing with signer, requests must carry token as a bearer token unless it is empty
+func NewHandler(signer gamevrf.Signer, token string) *Handler {
+	h := &Handler{
+		signer: signer,
+		token:  token,
+		mux:    http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc(PublicKeyPath, h.publicKey)
+	h.mux.HandleFunc(SignPath, h.sign)
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		got := []byte(r.Header.Get("Authorization"))
+		want := []byte("Bearer " + h.token)
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+// publicKey answers the public key of the signer
+func (h *Handler) publicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	pub, err := h.signer.PublicKey(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, &PublicKeyResponse{PublicKey: pub})
+}
+
+// sign answers the signature of the randomness drawn from the requested VRF inputs
+func (h *Handler) sign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// unknown fields are rejected so a raw message request doesn't sign the randomness of empty inputs
+	var req SignRequest
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&req)
+	if err != nil || len(req.RBase) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid sign request")
+		return
+	}
+
+	randomness, err := gamevrf.DrawRandomness(req.RBase, req.Pers, req.Height, req.Entropy)
+	if err != nil || len(randomness) != randomnessSize {
+		writeError(w, http.StatusBadRequest, "invalid VRF inputs")
+		return
+	}
+
+	sig, err := h.signer.Sign(r.Context(), randomness)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, &SignResponse{Signature: sig})
+}
+
+// writeJSON writes a successful answer
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a failed answer
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&ErrorResponse{Error: msg})
+}
@@ -0,0 +1,235 @@
+// Package remotesigner implements a gamevrf.VRFSigner talking HTTP/JSON to a signing daemon,
+// and the handler such a daemon serves, so game servers can generate VRFs without holding the key.
+// The daemon is sent the VRF inputs and draws the randomness it signs itself, it never signs a caller chosen message.
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	bls "github.com/drand/kyber-bls12381"
+	sign "github.com/drand/kyber/sign/bls"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// PublicKeyPath is the path of the public key request
+	PublicKeyPath = "/v1/publickey"
+	// SignPath is the path of the sign request
+	SignPath = "/v1/sign"
+)
+
+// PublicKeyResponse is the body answered to a public key request
+type PublicKeyResponse struct {
+	PublicKey []byte `json:"publicKey"`
+}
+
+// SignRequest is the body of a sign request, the VRF inputs the signed randomness is drawn from
+type SignRequest struct {
+	Pers    gamevrf.DomainSeparationTag `json:"pers"`
+	RBase   []byte                      `json:"rbase"`
+	Height  uint64                      `json:"height"`
+	Entropy []byte                      `json:"entropy"`
+}
+
+// SignResponse is the body answered to a sign request
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// ErrorResponse is the body answered to a failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Config is the configuration of a Signer
+type Config struct {
+	URL        string
+	Token      string
+	Timeout    time.Duration
+	HTTPClient *http.Client
+}
+
+// Option is a single Signer Config option.
+type Option func(cfg *Config)
+
+// TokenOption sends token as a bearer token with every request
+func TokenOption(token string) Option {
+	return func(cfg *Config) {
+		cfg.Token = token
+	}
+}
+
+// TimeoutOption specifies a time limit for every request
+func TimeoutOption(timeout time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.Timeout = timeout
+	}
+}
+
+// HTTPClientOption specifies the http Client used to talk to the daemon, http.DefaultClient is used if not set
+func HTTPClientOption(client *http.Client) Option {
+	return func(cfg *Config) {
+		cfg.HTTPClient = client
+	}
+}
+
+// Signer is a gamevrf.VRFSigner whose private key is held by a signing daemon, every signature
+// returned by the daemon is verified against its public key
+type Signer struct {
+	cfg Config
+
+	lck       sync.Mutex
+	publicKey []byte
+}
+
+var _ gamevrf.VRFSigner = (*Signer)(nil)
+
+// New creates a signer for the daemon at url
+func New(url string, options ...Option) *Signer {
+	cfg := Config{
+		URL:     strings.TrimRight(url, "/"),
+		Timeout: 30 * time.Second,
+	}
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	return &Signer{cfg: cfg}
+}
+
+// PublicKey implements gamevrf.Signer, the key is fetched once and remembered
+func (s *Signer) PublicKey(ctx context.Context) ([]byte, error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	if s.publicKey != nil {
+		return append([]byte(nil), s.publicKey...), nil
+	}
+
+	var rsp PublicKeyResponse
+	err := s.call(ctx, http.MethodGet, PublicKeyPath, nil, &rsp)
+	if err != nil {
+		return nil, xerrors.Errorf("remote signer PublicKey failed: %w", err)
+	}
+
+	if len(rsp.PublicKey) == 0 {
+		return nil, xerrors.New("remote signer returned an empty public key")
+	}
+
+	s.publicKey = rsp.PublicKey
+
+	return append([]byte(nil), s.publicKey...), nil
+}
+
+// Sign implements gamevrf.Signer, the daemon doesn't sign arbitrary messages so it always fails, use SignVRF
+func (s *Signer) Sign(ctx context.Context, msg []byte) ([]byte, error) {
+	return nil, xerrors.New("remote signer only signs VRF inputs, use SignVRF")
+}
+
+// SignVRF implements gamevrf.VRFSigner
+func (s *Signer) SignVRF(ctx context.Context, pers gamevrf.DomainSeparationTag, rbase []byte, height uint64, entropy []byte) ([]byte, error) {
+	pub, err := s.PublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	randomness, err := gamevrf.DrawRandomness(rbase, pers, height, entropy)
+	if err != nil {
+		return nil, xerrors.Errorf("remote signer SignVRF DrawRandomness failed: %w", err)
+	}
+
+	req := &SignRequest{
+		Pers:    pers,
+		RBase:   rbase,
+		Height:  height,
+		Entropy: entropy,
+	}
+
+	var rsp SignResponse
+	err = s.call(ctx, http.MethodPost, SignPath, req, &rsp)
+	if err != nil {
+		return nil, xerrors.Errorf("remote signer SignVRF failed: %w", err)
+	}
+
+	err = verify(pub, randomness, rsp.Signature)
+	if err != nil {
+		return nil, xerrors.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+
+	return rsp.Signature, nil
+}
+
+// call sends a request to the daemon and decodes the answer into out
+func (s *Signer) call(ctx context.Context, method, path string, in interface{}, out interface{}) error {
+	if s.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.URL+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	client := s.cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if json.Unmarshal(b, &e) == nil && e.Error != "" {
+			return xerrors.Errorf("status %d: %s", rsp.StatusCode, e.Error)
+		}
+		return xerrors.Errorf("status %d", rsp.StatusCode)
+	}
+
+	return json.Unmarshal(b, out)
+}
+
+// verify checks a BLS signature of msg by pub
+func verify(pub, msg, sig []byte) error {
+	suite := bls.NewBLS12381Suite()
+	p := suite.G1().Point()
+	err := p.UnmarshalBinary(pub)
+	if err != nil {
+		return err
+	}
+
+	return sign.NewSchemeOnG2(suite).Verify(p, msg, sig)
+}
@@ -0,0 +1,125 @@
+package gamevrf
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"github.com/drand/kyber"
+	bls "github.com/drand/kyber-bls12381"
+	kybersign "github.com/drand/kyber/sign"
+	sign "github.com/drand/kyber/sign/bls"
+	"github.com/filecoin-project/go-address"
+	"golang.org/x/xerrors"
+)
+
+// Signer produces the BLS signatures VRFs are made of, implementations may keep the private key
+// out of the process generating VRFs
+type Signer interface {
+	// PublicKey returns the 48 bytes compressed G1 public key of the signer
+	PublicKey(ctx context.Context) ([]byte, error)
+	// Sign returns the 96 bytes compressed G2 signature of msg
+	Sign(ctx context.Context, msg []byte) ([]byte, error)
+}
+
+// VRFSigner is a Signer drawing the VRF randomness itself from the VRF inputs, GenerateVRFWithSigner
+// uses SignVRF so a signer out of the process is never asked to sign an arbitrary message
+type VRFSigner interface {
+	Signer
+	// SignVRF returns the signature of the randomness drawn from rbase, pers, height and entropy
+	SignVRF(ctx context.Context, pers DomainSeparationTag, rbase []byte, height uint64, entropy []byte) ([]byte, error)
+}
+
+// LocalSigner is a Signer holding the private key in memory
+type LocalSigner struct {
+	scheme    kybersign.Scheme
+	scalar    kyber.Scalar
+	publicKey []byte
+}
+
+// NewLocalSigner creates a signer from a Filecoin BLS private key
+func NewLocalSigner(filBlsPrivateKey []byte) (*LocalSigner, error) {
+	suite := bls.NewBLS12381Suite()
+	sc := suite.G1().Scalar()
+	err := sc.UnmarshalBinary(FilBlsKey2KyberBlsKey(filBlsPrivateKey))
+	if err != nil {
+		return nil, xerrors.Errorf("NewLocalSigner UnmarshalBinary failed: %w", err)
+	}
+
+	pub, err := suite.G1().Point().Mul(sc, nil).MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("NewLocalSigner MarshalBinary failed: %w", err)
+	}
+
+	return &LocalSigner{
+		scheme:    sign.NewSchemeOnG2(suite),
+		scalar:    sc,
+		publicKey: pub,
+	}, nil
+}
+
+// PublicKey implements Signer
+func (s *LocalSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	return append([]byte(nil), s.publicKey...), nil
+}
+
+// Sign implements Signer
+func (s *LocalSigner) Sign(ctx context.Context, msg []byte) ([]byte, error) {
+	return s.scheme.Sign(s.scalar, msg)
+}
+
+// SignerAddress returns the f3/t3 address of the signer
+func SignerAddress(ctx context.Context, signer Signer) (address.Address, error) {
+	pub, err := signer.PublicKey(ctx)
+	if err != nil {
+		return address.Undef, xerrors.Errorf("SignerAddress PublicKey failed: %w", err)
+	}
+
+	return address.NewBLSAddress(pub)
+}
+
+// GenerateVRFWithSigner is like GenerateVRF but the proof is signed by signer
+func GenerateVRFWithSigner(ctx context.Context, pers DomainSeparationTag,
+	signer Signer, rbase []byte, height uint64, entropy []byte) (*VRFOut, error) {
+
+	if vs, ok := signer.(VRFSigner); ok {
+		vrf, err := vs.SignVRF(ctx, pers, rbase, height, entropy)
+		if err != nil {
+			return nil, xerrors.Errorf("GenerateVRFWithSigner SignVRF failed: %w", err)
+		}
+
+		return &VRFOut{
+			Height: height,
+			Proof:  vrf,
+		}, nil
+	}
+
+	// draw randomness
+	randomness, err := drawRandomness(rbase, pers, height, entropy)
+	if err != nil {
+		return nil, xerrors.Errorf("GenerateVRFWithSigner drawRandomness failed: %w", err)
+	}
+
+	// compute vrf
+	vrf, err := signer.Sign(ctx, randomness)
+	if err != nil {
+		return nil, xerrors.Errorf("GenerateVRFWithSigner Sign failed: %w", err)
+	}
+
+	return &VRFOut{
+		Height: height,
+		Proof:  vrf,
+	}, nil
+}
+
+// FilGenerateVRFByTipSetWithSigner is like FilGenerateVRFByTipSet but the proof is signed by signer
+func FilGenerateVRFByTipSetWithSigner(ctx context.Context, pers DomainSeparationTag,
+	signer Signer, ts *filrpc.TipSet, entropy []byte) (*VRFOut, error) {
+	if len(ts.Blocks()) == 0 {
+		return nil, xerrors.Errorf("FilGenerateVRFByTipSetWithSigner no block in tipset(height:%d)", ts.Height())
+	}
+
+	// use min ticket
+	minTicket := ts.MinTicket()
+	return GenerateVRFWithSigner(ctx, pers, signer, minTicket.VRFProof, ts.Height(), entropy)
+}
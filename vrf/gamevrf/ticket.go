@@ -0,0 +1,244 @@
+package gamevrf
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// FILECOIN_MAINNET_SMOKE_HEIGHT is the mainnet height after which the parent min ticket is part of the ticket input
+	FILECOIN_MAINNET_SMOKE_HEIGHT = 51000
+	// FILECOIN_TICKET_RANDOMNESS_LOOKBACK is the number of epochs between the block and the ticket randomness round
+	FILECOIN_TICKET_RANDOMNESS_LOOKBACK = 1
+	// FILECOIN_CHAIN_FINALITY is the number of epochs after which the chain is considered final, miner state is read this far back
+	FILECOIN_CHAIN_FINALITY = 900
+	// TICKET_BEACON_SEARCH_LIMIT is the number of tipsets walked back to find the latest beacon entry of a block without one
+	TICKET_BEACON_SEARCH_LIMIT = 20
+)
+
+// filTicketProductionTag is the filecoin DomainSeparationTag_TicketProduction
+const filTicketProductionTag DomainSeparationTag = 1
+
+// WorkerKeyResolver resolves the BLS worker key a miner used to sign the block at height
+type WorkerKeyResolver interface {
+	WorkerKey(ctx context.Context, miner address.Address, height uint64) (address.Address, error)
+}
+
+// LotusWorkerResolver resolves worker keys from the miner state of a lotus node. The keys are only as trustworthy
+// as that node: a resolver talking to the node serving the tipsets lets it vouch for its own forged tickets, use a
+// separate trusted endpoint or pinned WorkerKeys.
+type LotusWorkerResolver struct {
+	client *filrpc.Client
+}
+
+// NewLotusWorkerResolver creates a WorkerKeyResolver that talks to the lotus node configured by options,
+// which should not be the node the tipsets are fetched from
+func NewLotusWorkerResolver(options ...filrpc.Option) *LotusWorkerResolver {
+	return &LotusWorkerResolver{
+		client: filrpc.New(options...),
+	}
+}
+
+// WorkerKey implements WorkerKeyResolver, the miner state is read at the finality lookback of height as lotus does
+func (r *LotusWorkerResolver) WorkerKey(ctx context.Context, miner address.Address, height uint64) (address.Address, error) {
+	var lookback int64
+	if height > FILECOIN_CHAIN_FINALITY {
+		lookback = int64(height - FILECOIN_CHAIN_FINALITY)
+	}
+
+	lbts, err := r.client.ChainGetTipSetByHeightContext(ctx, lookback)
+	if err != nil {
+		return address.Undef, xerrors.Errorf("WorkerKey get lookback tipset failed: %w", err)
+	}
+
+	info, err := r.client.StateMinerInfoContext(ctx, miner, lbts.Cids())
+	if err != nil {
+		return address.Undef, xerrors.Errorf("WorkerKey StateMinerInfo failed: %w", err)
+	}
+
+	key, err := r.client.StateAccountKeyContext(ctx, info.Worker, lbts.Cids())
+	if err != nil {
+		return address.Undef, xerrors.Errorf("WorkerKey StateAccountKey failed: %w", err)
+	}
+
+	return key, nil
+}
+
+// MinerWorker maps a miner to its BLS worker key
+type MinerWorker struct {
+	Miner  address.Address
+	Worker address.Address
+}
+
+// WorkerKeys is a WorkerKeyResolver over a fixed set of miners
+type WorkerKeys map[address.Address]address.Address
+
+// NewWorkerKeys creates WorkerKeys from a list of miner workers
+func NewWorkerKeys(workers []MinerWorker) WorkerKeys {
+	keys := make(WorkerKeys)
+	for _, w := range workers {
+		keys[w.Miner] = w.Worker
+	}
+
+	return keys
+}
+
+// WorkerKey implements WorkerKeyResolver
+func (k WorkerKeys) WorkerKey(ctx context.Context, miner address.Address, height uint64) (address.Address, error) {
+	key, ok := k[miner]
+	if !ok {
+		return address.Undef, xerrors.Errorf("WorkerKey unknown miner %s", miner)
+	}
+
+	return key, nil
+}
+
+// TicketVerifier checks that the min ticket of a tipset is a valid VRF of its miner instead of trusting the rpc node
+type TicketVerifier struct {
+	provider    TipSetProvider
+	workers     WorkerKeyResolver
+	smokeHeight uint64
+}
+
+// NewTicketVerifier creates a TicketVerifier reading parent tipsets from provider and worker keys from workers.
+// smokeHeight is the network's smoke upgrade height, FILECOIN_MAINNET_SMOKE_HEIGHT for mainnet and 0 for calibration
+func NewTicketVerifier(provider TipSetProvider, workers WorkerKeyResolver, smokeHeight uint64) *TicketVerifier {
+	return &TicketVerifier{
+		provider:    provider,
+		workers:     workers,
+		smokeHeight: smokeHeight,
+	}
+}
+
+// VerifyMinTicket verifies the ticket of the min ticket block of ts
+func (v *TicketVerifier) VerifyMinTicket(ctx context.Context, ts *filrpc.TipSet) error {
+	if len(ts.Blocks()) == 0 {
+		return xerrors.Errorf("VerifyMinTicket no block in tipset(height:%d)", ts.Height())
+	}
+
+	if ts.Height() == 0 {
+		return xerrors.Errorf("VerifyMinTicket genesis has no ticket")
+	}
+
+	blk := ts.MinTicketBlock()
+	parent, err := v.provider.ChainGetTipSetByHeight(ctx, int64(ts.Height())-1)
+	if err != nil {
+		return xerrors.Errorf("VerifyMinTicket get parent tipset failed: %w", err)
+	}
+
+	// a node dropping the parents or the parent cids must not skip the check
+	if len(blk.Parents) == 0 || len(parent.Cids()) == 0 {
+		return xerrors.Errorf("VerifyMinTicket missing the parents of the block at height %d", blk.Height)
+	}
+
+	if !sameCids(blk.Parents, parent.Cids()) {
+		return xerrors.Errorf("VerifyMinTicket parent tipset at height %d is not the block's parent", parent.Height())
+	}
+
+	beacon, err := v.latestBeacon(ctx, blk, parent)
+	if err != nil {
+		return xerrors.Errorf("VerifyMinTicket %w", err)
+	}
+
+	worker, err := v.workers.WorkerKey(ctx, blk.Miner, blk.Height)
+	if err != nil {
+		return xerrors.Errorf("VerifyMinTicket resolve worker key failed: %w", err)
+	}
+
+	return VerifyBlockTicket(blk, parent, beacon, worker, v.smokeHeight)
+}
+
+// latestBeacon returns the beacon entry the ticket of blk is drawn from, searching the parents if blk has none
+func (v *TicketVerifier) latestBeacon(ctx context.Context, blk *filrpc.BlockHeader, parent *filrpc.TipSet) (*filrpc.BeaconEntry, error) {
+	if len(blk.BeaconEntries) > 0 {
+		return &blk.BeaconEntries[len(blk.BeaconEntries)-1], nil
+	}
+
+	ts := parent
+	for i := 0; i < TICKET_BEACON_SEARCH_LIMIT; i++ {
+		entries := ts.Blocks()[0].BeaconEntries
+		if len(entries) > 0 {
+			return &entries[len(entries)-1], nil
+		}
+
+		if ts.Height() == 0 {
+			break
+		}
+
+		var err error
+		ts, err = v.provider.ChainGetTipSetByHeight(ctx, int64(ts.Height())-1)
+		if err != nil {
+			return nil, xerrors.Errorf("get tipset searching beacon entry failed: %w", err)
+		}
+	}
+
+	return nil, xerrors.Errorf("no beacon entry found within %d tipsets of height %d", TICKET_BEACON_SEARCH_LIMIT, blk.Height)
+}
+
+// TicketVRFInput returns the message the miner signs to produce the ticket of blk
+func TicketVRFInput(blk *filrpc.BlockHeader, parent *filrpc.TipSet, beacon *filrpc.BeaconEntry, smokeHeight uint64) ([]byte, error) {
+	if blk.Height < FILECOIN_TICKET_RANDOMNESS_LOOKBACK {
+		return nil, xerrors.Errorf("TicketVRFInput invalid block height %d", blk.Height)
+	}
+
+	buf := new(bytes.Buffer)
+	err := blk.Miner.MarshalCBOR(buf)
+	if err != nil {
+		return nil, xerrors.Errorf("TicketVRFInput marshal miner address failed: %w", err)
+	}
+
+	if blk.Height > smokeHeight {
+		buf.Write(parent.MinTicket().VRFProof)
+	}
+
+	return drawRandomness(beacon.Data, filTicketProductionTag, blk.Height-FILECOIN_TICKET_RANDOMNESS_LOOKBACK, buf.Bytes())
+}
+
+// VerifyBlockTicket verifies that the ticket of blk is a valid VRF of the worker over the protocol defined input
+func VerifyBlockTicket(blk *filrpc.BlockHeader, parent *filrpc.TipSet, beacon *filrpc.BeaconEntry, worker address.Address, smokeHeight uint64) error {
+	if blk.Ticket == nil {
+		return xerrors.Errorf("VerifyBlockTicket block has no ticket")
+	}
+
+	if worker.Protocol() != address.BLS {
+		return xerrors.Errorf("VerifyBlockTicket worker %s is not a BLS address", worker)
+	}
+
+	input, err := TicketVRFInput(blk, parent, beacon, smokeHeight)
+	if err != nil {
+		return xerrors.Errorf("VerifyBlockTicket %w", err)
+	}
+
+	err = blsVerify(worker.Payload(), input, blk.Ticket.VRFProof)
+	if err != nil {
+		return xerrors.Errorf("VerifyBlockTicket invalid ticket of miner %s at height %d: %w", blk.Miner, blk.Height, err)
+	}
+
+	return nil
+}
+
+// sameCids reports whether a and b hold the same cids regardless of order
+func sameCids(a, b []cid.Cid) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[cid.Cid]struct{}, len(a))
+	for _, c := range a {
+		set[c] = struct{}{}
+	}
+
+	for _, c := range b {
+		if _, ok := set[c]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
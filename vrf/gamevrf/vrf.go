@@ -1,6 +1,7 @@
 package gamevrf
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -13,13 +14,24 @@ import (
 const (
 	// FILECOIN_EPOCH_DURATION represents the duration of a Filecoin epoch in seconds
 	FILECOIN_EPOCH_DURATION = 30
-	// GAME_CHAIN_EPOCH_LOOKBACK represents the number of epochs to look back when fetching tipsets for the game chain
+	// GAME_CHAIN_EPOCH_LOOKBACK represents the default number of epochs to look back when fetching tipsets for the game chain
 	GAME_CHAIN_EPOCH_LOOKBACK = 10
 )
 
 // GameVRF represents a VRF implementation for the game
 type GameVRF struct {
-	rpcOptions []filrpc.Option
+	provider       TipSetProvider
+	workers        WorkerKeyResolver
+	smokeHeight    uint64
+	ticketVerifier *TicketVerifier
+	cacheSize      int
+	cacheStore     TipSetStore
+	cache          *CachedProvider
+	policy         Policy
+	tags           *TagRegistry
+	drandInfo      *DrandChainInfo
+	drandSource    DrandSource
+	head           *HeadTracker
 
 	lck             sync.Mutex
 	isCacheValid    bool // use cache to reduce 'ChainHead' calls
@@ -29,36 +41,76 @@ type GameVRF struct {
 
 // New creates a new instance of GameVRF with the specified RPC options
 func New(options ...filrpc.Option) *GameVRF {
-	return &GameVRF{
-		rpcOptions: options,
+	return NewWithProvider(NewLotusProvider(options...))
+}
+
+// NewWithProvider creates a new instance of GameVRF fetching tipsets from provider with the specified options,
+// a lotus node with the default filrpc config if provider is nil
+func NewWithProvider(provider TipSetProvider, options ...Option) *GameVRF {
+	g := &GameVRF{provider: provider, policy: DefaultPolicy()}
+	for _, opt := range options {
+		opt(g)
+	}
+
+	if g.provider == nil {
+		g.provider = NewLotusProvider()
+	}
+
+	if g.cacheSize > 0 {
+		g.cache = NewCachedProvider(g.provider, g.cacheSize, g.cacheStore)
+		g.provider = g.cache
+	}
+
+	if g.workers != nil {
+		g.ticketVerifier = NewTicketVerifier(g.provider, g.workers, g.smokeHeight)
 	}
+
+	return g
 }
 
-// getTipsetByHeight retrieves a non-empty tipset at the specified height or within the lookback window
-func (g *GameVRF) getTipsetByHeight(height uint64) (*filrpc.TipSet, error) {
-	client := filrpc.New(g.rpcOptions...)
+// CacheStats returns the statistics of the tipset cache, all zero if TipSetCacheOption isn't used
+func (g *GameVRF) CacheStats() CacheStats {
+	if g.cache == nil {
+		return CacheStats{}
+	}
+
+	return g.cache.Stats()
+}
+
+// getTipsetByHeight retrieves a non-empty tipset at the specified height, null rounds are searched as the policy says
+func (g *GameVRF) getTipsetByHeight(ctx context.Context, height uint64) (*filrpc.TipSet, error) {
+	tps, err := g.findTipSet(ctx, height)
+	if err != nil {
+		return nil, xerrors.Errorf("getTipsetByHeight %w", err)
+	}
 
-	iheight := int64(height)
-	for i := 0; i < GAME_CHAIN_EPOCH_LOOKBACK && iheight > 0; i++ {
-		tps, err := client.ChainGetTipSetByHeight(iheight)
+	if g.ticketVerifier != nil {
+		err = g.ticketVerifier.VerifyMinTicket(ctx, tps)
 		if err != nil {
-			return nil, err
+			return nil, xerrors.Errorf("getTipsetByHeight %w", err)
 		}
+	}
 
-		if len(tps.Blocks()) > 0 {
-			return tps, nil
-		}
+	return tps, nil
+}
 
-		iheight--
+// checkTag rejects tags missing from the registry given by TagRegistryOption
+func (g *GameVRF) checkTag(pers DomainSeparationTag) error {
+	if g.tags == nil {
+		return nil
 	}
 
-	return nil, xerrors.Errorf("getTipsetByHeight can't found a non-empty tipset from height: %d", height)
+	return g.tags.Check(pers)
 }
 
-// getChainHead retrieves the current chain head height
-func (g *GameVRF) getChainHead() (uint64, error) {
-	client := filrpc.New(g.rpcOptions...)
-	tps, err := client.ChainHead()
+// getChainHead retrieves the current chain head height, from the head tracker if there is one
+func (g *GameVRF) getChainHead(ctx context.Context) (uint64, error) {
+	var provider TipSetProvider = g.provider
+	if g.head != nil {
+		provider = g.head
+	}
+
+	tps, err := provider.ChainHead(ctx)
 	if err != nil {
 		return 0, xerrors.Errorf("getChainHead ChainHead call failed: %w", err)
 	}
@@ -68,12 +120,17 @@ func (g *GameVRF) getChainHead() (uint64, error) {
 
 // ForceUpdateCachedEpoch forces an update of the cached epoch and returns the new epoch
 func (g *GameVRF) ForceUpdateCachedEpoch() (uint64, error) {
+	return g.ForceUpdateCachedEpochContext(context.Background())
+}
+
+// ForceUpdateCachedEpochContext is like ForceUpdateCachedEpoch but the chain head request is canceled with ctx
+func (g *GameVRF) ForceUpdateCachedEpochContext(ctx context.Context) (uint64, error) {
 	g.lck.Lock()
 	defer g.lck.Unlock()
 
 	g.isCacheValid = false
 	g.cachedTimestamp = time.Now()
-	h, err := g.getChainHead()
+	h, err := g.getChainHead(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -84,14 +141,18 @@ func (g *GameVRF) ForceUpdateCachedEpoch() (uint64, error) {
 	return h, nil
 }
 
-// getGameEpoch retrieves the current game epoch, updating the cache if necessary
-func (g *GameVRF) getGameEpoch() (uint64, error) {
+// getGameEpoch retrieves the current game epoch, the tracked head or the cached head plus the elapsed epochs
+func (g *GameVRF) getGameEpoch(ctx context.Context) (uint64, error) {
+	if g.head != nil {
+		return g.getChainHead(ctx)
+	}
+
 	g.lck.Lock()
 	defer g.lck.Unlock()
 
 	if !g.isCacheValid {
 		g.cachedTimestamp = time.Now()
-		h, err := g.getChainHead()
+		h, err := g.getChainHead(ctx)
 		if err != nil {
 			return 0, err
 		}
@@ -112,30 +173,112 @@ func (g *GameVRF) getGameEpoch() (uint64, error) {
 
 // GenerateVRF generates a VRF output given the domain separation tag, Filecoin BLS private key, and entropy
 func (g *GameVRF) GenerateVRF(pers DomainSeparationTag, filBlsPrivateKey []byte, entropy []byte) (*VRFOut, error) {
-	height, err := g.getGameEpoch()
+	return g.GenerateVRFContext(context.Background(), pers, filBlsPrivateKey, entropy)
+}
+
+// GenerateVRFContext is like GenerateVRF but all chain requests are canceled with ctx
+func (g *GameVRF) GenerateVRFContext(ctx context.Context, pers DomainSeparationTag, filBlsPrivateKey []byte, entropy []byte) (*VRFOut, error) {
+	vrf, _, err := g.GenerateVRFWithMeta(ctx, pers, filBlsPrivateKey, entropy)
+	return vrf, err
+}
+
+// GenerateVRFWithMeta is like GenerateVRFContext but also reports how the VRF base was chosen
+func (g *GameVRF) GenerateVRFWithMeta(ctx context.Context, pers DomainSeparationTag, filBlsPrivateKey []byte, entropy []byte) (*VRFOut, *VRFMeta, error) {
+	signer, err := NewLocalSigner(filBlsPrivateKey)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("GenerateVRF %w", err)
+	}
+
+	return g.GenerateVRFWithSigner(ctx, pers, signer, entropy)
+}
+
+// GenerateVRFWithSigner is like GenerateVRFWithMeta but the proof is signed by signer, the private key
+// may be kept by a remote signing service
+func (g *GameVRF) GenerateVRFWithSigner(ctx context.Context, pers DomainSeparationTag, signer Signer, entropy []byte) (*VRFOut, *VRFMeta, error) {
+	err := g.checkTag(pers)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("GenerateVRF %w", err)
+	}
+
+	if g.drandSource != nil {
+		return g.generateDrandVRF(ctx, pers, signer, entropy)
+	}
+
+	height, err := g.getGameEpoch(ctx)
 	if err != nil {
-		return nil, xerrors.Errorf("GenerateVRF getGameEpoch failed: %w", err)
+		return nil, nil, xerrors.Errorf("GenerateVRF getGameEpoch failed: %w", err)
 	}
 
-	if height <= GAME_CHAIN_EPOCH_LOOKBACK {
-		return nil, xerrors.Errorf("GenerateVRF getGameEpoch return invalid height: %d", height)
+	if height <= g.policy.Lookback {
+		return nil, nil, xerrors.Errorf("GenerateVRF getGameEpoch return invalid height: %d", height)
 	}
 
-	lookback := height - GAME_CHAIN_EPOCH_LOOKBACK
-	tps, err := g.getTipsetByHeight(lookback)
+	lookback := height - g.policy.Lookback
+	tps, err := g.getTipsetByHeight(ctx, lookback)
 	if err != nil {
-		return nil, xerrors.Errorf("GenerateVRF getTipsetByHeight failed: %w", err)
+		return nil, nil, xerrors.Errorf("GenerateVRF getTipsetByHeight failed: %w", err)
+	}
+
+	head, confirmations, err := g.checkConfirmations(ctx, tps.Height())
+	if err != nil {
+		return nil, nil, xerrors.Errorf("GenerateVRF %w", err)
+	}
+
+	vrf, err := FilGenerateVRFByTipSetWithSigner(ctx, pers, signer, tps, entropy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &VRFMeta{
+		GameEpoch:       height,
+		RequestedHeight: lookback,
+		BaseHeight:      tps.Height(),
+		HeadHeight:      head,
+		Confirmations:   confirmations,
+	}
+	if tps.Height() < lookback {
+		meta.NullRounds = lookback - tps.Height()
+	} else {
+		meta.NullRounds = tps.Height() - lookback
 	}
 
-	return FilGenerateVRFByTipSet(pers, filBlsPrivateKey, tps, entropy)
+	return vrf, meta, nil
 }
 
 // VerifyVRF verifies a VRF output given the domain separation tag, worker address, entropy, and the VRF output
 func (g *GameVRF) VerifyVRF(pers DomainSeparationTag, worker address.Address, entropy []byte, vrf *VRFOut) error {
-	tps, err := g.getTipsetByHeight(vrf.Height)
+	return g.VerifyVRFContext(context.Background(), pers, worker, entropy, vrf)
+}
+
+// VerifyVRFContext is like VerifyVRF but all chain requests are canceled with ctx
+func (g *GameVRF) VerifyVRFContext(ctx context.Context, pers DomainSeparationTag, worker address.Address, entropy []byte, vrf *VRFOut) error {
+	err := g.checkTag(pers)
+	if err != nil {
+		return xerrors.Errorf("VerifyVRF %w", err)
+	}
+
+	switch vrf.BaseKind {
+	case BaseKind_MinTicket:
+	case BaseKind_Drand:
+		b, err := g.drandBase(ctx, vrf.Height)
+		if err != nil {
+			return xerrors.Errorf("VerifyVRF drand base failed: %w", err)
+		}
+
+		return VerifyVRF(worker.Payload(), pers, b.Signature, entropy, vrf)
+	default:
+		return xerrors.Errorf("VerifyVRF unknown base kind %s", vrf.BaseKind)
+	}
+
+	tps, err := g.getTipsetByHeight(ctx, vrf.Height)
 	if err != nil {
 		return xerrors.Errorf("VerifyVRF getTipsetByHeight failed: %w", err)
 	}
 
+	_, _, err = g.checkConfirmations(ctx, tps.Height())
+	if err != nil {
+		return xerrors.Errorf("VerifyVRF %w", err)
+	}
+
 	return FilVerifyVRFByTipSet(pers, worker, tps, entropy, vrf)
 }
@@ -1,16 +1,51 @@
 package gamevrf
 
 import (
+	"encoding/binary"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+
 	"github.com/minio/blake2b-simd"
 )
 
+// vrfDeriveContext prefixes every derivation so derived seeds never equal a hash of the proof made for another use
+const vrfDeriveContext = "titan-game-sdk/vrf-derive/v1"
+
 // VRFOut represents the output of a Verifiable Random Function (VRF)
 type VRFOut struct {
 	Height uint64 // Height of the block associated with the VRF output
 	Proof  []byte // Proof generated by the VRF
+
+	BaseKind BaseKind // Kind of the VRF base, Height is a drand round for BaseKind_Drand
 }
 
 // Sum256 computes a 32-byte hash (blake2b) of the VRF proof
 func (vrf *VRFOut) Sum256() [32]byte {
 	return blake2b.Sum256(vrf.Proof)
 }
+
+// Derive returns the sub-seed of the stream named label and index, a blake2b-256 MAC keyed with Sum256 over
+// the derivation context, the length prefixed label and the big endian index. Sub-seeds of different
+// labels or indexes are independent, and anyone holding the proof derives the same ones.
+func (vrf *VRFOut) Derive(label string, index uint64) [32]byte {
+	seed := vrf.Sum256()
+
+	h := blake2b.NewMAC(32, seed[:])
+	var n [8]byte
+	h.Write([]byte(vrfDeriveContext))
+	binary.BigEndian.PutUint64(n[:], uint64(len(label)))
+	h.Write(n[:])
+	h.Write([]byte(label))
+	binary.BigEndian.PutUint64(n[:], index)
+	h.Write(n[:])
+
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// DeriveRng returns a random number generator of type typ seeded with Derive(label, index),
+// version is the trand algorithm version and defaults to trand.AlgorithmVersion_Legacy
+func (vrf *VRFOut) DeriveRng(label string, index uint64, typ trand.RNGType, version ...trand.AlgorithmVersion) trand.RngV1 {
+	return trand.NewRng(vrf.Derive(label, index), typ, version...)
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+)
+
+func TestSourceAdapter(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_Latest)
+	src := trand.NewSource(trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_Latest))
+
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			if a, b := r.Uint64(), src.Uint64(); a != b {
+				t.Fatalf("Uint64 #%d %d != %d", i, b, a)
+			}
+		} else if a, b := int64(r.Uint64()>>1), src.Int63(); a != b {
+			t.Fatalf("Int63 #%d %d != %d", i, b, a)
+		}
+	}
+
+	// a library shuffling with a *rand.Rand gets the same result for the same seed
+	shuffle := func() []int {
+		rnd := trand.NewRand(trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest))
+		s := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		rnd.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+		return s
+	}
+
+	a, b := shuffle(), shuffle()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("shuffle %v != %v", b, a)
+		}
+	}
+
+	// reseeding doesn't change the draws
+	src.Seed(1)
+	if a, b := r.Uint64(), src.Uint64(); a != b {
+		t.Fatalf("Uint64 after Seed %d != %d", b, a)
+	}
+}
+
+func TestReaderAdapter(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_ChaCha20)
+	expect := make([]byte, 8*64)
+	for i := 0; i < len(expect); i += 8 {
+		binary.LittleEndian.PutUint64(expect[i:], r.Uint64())
+	}
+
+	// reads of any size give the same stream
+	for _, size := range []int{1, 3, 8, 13, 64, len(expect)} {
+		rd := trand.NewReader(trand.NewRng(trandSeed(), trand.RNGType_ChaCha20))
+		var got []byte
+		buf := make([]byte, size)
+		for len(got) < len(expect) {
+			n, err := rd.Read(buf[:size])
+			if err != nil || n != size {
+				t.Fatalf("read %d of %d bytes: %v", n, size, err)
+			}
+			got = append(got, buf[:n]...)
+		}
+
+		if !bytes.Equal(got[:len(expect)], expect) {
+			t.Fatalf("stream of %d bytes reads doesn't match the draws", size)
+		}
+	}
+
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(trand.NewReader(trand.NewRng(trandSeed(), trand.RNGType_ChaCha20)), b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, expect[:32]) {
+		t.Fatal("io.ReadFull doesn't match the draws")
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+)
+
+// batchItems generates n valid batch items signed alternately by the test key and a random key
+func batchItems(tb testing.TB, n int) []gamevrf.VRFBatchItem {
+	otherPrivateKey, otherPublicKey, err := gamevrf.KyberBlsGenPrivateKey()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	items := make([]gamevrf.VRFBatchItem, 0, n)
+	for i := 0; i < n; i++ {
+		privateKey, publicKey := gamevrf.FilBlsKey2KyberBlsKey(filPrivateKey), filPublicKey
+		if i%2 == 1 {
+			privateKey, publicKey = otherPrivateKey, otherPublicKey
+		}
+
+		rbase := []byte(fmt.Sprintf("rbase-%d", i%5))
+		entropy := []byte(fmt.Sprintf("entropy-%d", i))
+		height := uint64(3303964 + i)
+
+		vrfout, err := gamevrf.GenerateVRF(gamevrf.DomainSeparationTag_GameRound, privateKey, rbase, height, entropy)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		items = append(items, gamevrf.VRFBatchItem{
+			PublicKey: publicKey,
+			Pers:      gamevrf.DomainSeparationTag_GameRound,
+			RBase:     rbase,
+			Entropy:   entropy,
+			VRF:       vrfout,
+		})
+	}
+
+	return items
+}
+
+func TestVerifyVRFBatch(t *testing.T) {
+	items := batchItems(t, 16)
+
+	err := gamevrf.VerifyVRFBatch(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyVRFBatchInvalid(t *testing.T) {
+	items := batchItems(t, 16)
+
+	// wrong entropy, a proof swapped with another item and an undecodable proof
+	items[3].Entropy = []byte("tampered")
+	items[8].VRF, items[10].VRF = items[10].VRF, items[8].VRF
+	items[12].VRF = &gamevrf.VRFOut{Height: items[12].VRF.Height, Proof: []byte{1, 2, 3}}
+
+	err := gamevrf.VerifyVRFBatch(items)
+
+	var batchErr *gamevrf.VRFBatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expect VRFBatchError, got %v", err)
+	}
+
+	bad := make(map[int]bool)
+	for _, idx := range batchErr.Indexes {
+		bad[idx] = true
+	}
+
+	for _, idx := range []int{3, 8, 10, 12} {
+		if !bad[idx] {
+			t.Fatalf("item %d should be reported invalid, got %v", idx, batchErr.Indexes)
+		}
+	}
+
+	if len(batchErr.Indexes) != 4 {
+		t.Fatalf("expect 4 invalid items, got %v", batchErr.Indexes)
+	}
+}
+
+func benchmarkVerify(b *testing.B, n int, batch bool) {
+	items := batchItems(b, n)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batch {
+			if err := gamevrf.VerifyVRFBatch(items); err != nil {
+				b.Fatal(err)
+			}
+			continue
+		}
+
+		for _, item := range items {
+			if err := gamevrf.VerifyVRF(item.PublicKey, item.Pers, item.RBase, item.Entropy, item.VRF); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifyVRFLoop16(b *testing.B)  { benchmarkVerify(b, 16, false) }
+func BenchmarkVerifyVRFBatch16(b *testing.B) { benchmarkVerify(b, 16, true) }
+func BenchmarkVerifyVRFLoop64(b *testing.B)  { benchmarkVerify(b, 64, false) }
+func BenchmarkVerifyVRFBatch64(b *testing.B) { benchmarkVerify(b, 64, true) }
@@ -0,0 +1,208 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// mapStore is an in memory TipSetStore
+type mapStore struct {
+	lck     sync.Mutex
+	tipsets map[int64]*filrpc.TipSet
+}
+
+func (s *mapStore) Get(height int64) (*filrpc.TipSet, bool, error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	ts, ok := s.tipsets[height]
+	return ts, ok, nil
+}
+
+func (s *mapStore) Put(height int64, ts *filrpc.TipSet) error {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	s.tipsets[height] = ts
+	return nil
+}
+
+func TestCachedProviderCoalescing(t *testing.T) {
+	m := newMockLotus(t, 2000)
+	m.SetDelay(100 * time.Millisecond)
+
+	gg := gamevrf.NewWithProvider(
+		gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())),
+		gamevrf.TipSetCacheOption(16, nil),
+	)
+
+	ts := m.TipSet(1500)
+	entropy := []byte("cache-entropy")
+	vrfout, err := gamevrf.FilGenerateVRFByTipSet(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, ts, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- gg.VerifyVRF(gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = gg.VerifyVRF(gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := m.Calls("Filecoin.ChainGetTipSetByHeight"); n != 1 {
+		t.Fatalf("expect a single fetch, got %d", n)
+	}
+
+	stats := gg.CacheStats()
+	if stats.Misses != 1 || stats.Hits+stats.Shared != 10 {
+		t.Fatalf("unexpected cache stats %+v", stats)
+	}
+
+	if stats.HitRate() < 0.9 {
+		t.Fatalf("hit rate %f < 0.9", stats.HitRate())
+	}
+}
+
+func TestCachedProviderEviction(t *testing.T) {
+	m := newMockLotus(t, 2000)
+	p := gamevrf.NewCachedProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), 2, nil)
+
+	ctx := context.Background()
+	for _, h := range []int64{100, 101, 100, 102, 101} {
+		_, err := p.ChainGetTipSetByHeight(ctx, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 101 was evicted by 102 since 100 was used more recently
+	stats := p.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 {
+		t.Fatalf("unexpected cache stats %+v", stats)
+	}
+}
+
+func TestCachedProviderStore(t *testing.T) {
+	store := &mapStore{tipsets: make(map[int64]*filrpc.TipSet)}
+
+	m := newMockLotus(t, 2000)
+	p := gamevrf.NewCachedProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), 8, store)
+
+	ctx := context.Background()
+	for _, h := range []int64{1000, 1950} {
+		_, err := p.ChainGetTipSetByHeight(ctx, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok, _ := store.Get(1000); !ok {
+		t.Fatal("finalized height 1000 should be stored")
+	}
+	if _, ok, _ := store.Get(1950); ok {
+		t.Fatal("height 1950 isn't final and shouldn't be stored")
+	}
+
+	// a fresh provider reads finalized heights from the store without fetching
+	m2 := newMockLotus(t, 2000)
+	p2 := gamevrf.NewCachedProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m2.URL())), 8, store)
+	ts, err := p2.ChainGetTipSetByHeight(ctx, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ts.Height() != 1000 || m2.Calls("Filecoin.ChainGetTipSetByHeight") != 0 {
+		t.Fatal("height 1000 should be served from the store")
+	}
+
+	if p2.Stats().StoreHits != 1 {
+		t.Fatalf("unexpected cache stats %+v", p2.Stats())
+	}
+}
+
+func TestCachedProviderLeaderCancel(t *testing.T) {
+	m := newMockLotus(t, 2000)
+	m.SetDelay(300 * time.Millisecond)
+	p := gamevrf.NewCachedProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), 8, nil)
+
+	leaderCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := p.ChainGetTipSetByHeight(leaderCtx, 1500)
+		leaderErr <- err
+	}()
+
+	// join the fetch started by the leader
+	time.Sleep(10 * time.Millisecond)
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 1500)
+	if err != nil {
+		t.Fatalf("waiter failed with the leader ctx: %v", err)
+	}
+
+	if ts.Height() != 1500 {
+		t.Fatalf("unexpected height %d", ts.Height())
+	}
+
+	if err := <-leaderErr; err == nil {
+		t.Fatal("leader should fail with its own ctx")
+	}
+
+	if calls := m.Calls("Filecoin.ChainGetTipSetByHeight"); calls != 1 {
+		t.Fatalf("expected a single shared fetch, got %d", calls)
+	}
+}
+
+func TestCachedProviderAllWaitersLeave(t *testing.T) {
+	m := newMockLotus(t, 2000)
+	m.SetDelay(200 * time.Millisecond)
+	p := gamevrf.NewCachedProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), 8, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := p.ChainGetTipSetByHeight(ctx, 1500)
+	if err == nil {
+		t.Fatal("expected the ctx error")
+	}
+
+	// the canceled fetch isn't shared with later callers
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 1500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ts.Height() != 1500 {
+		t.Fatalf("unexpected height %d", ts.Height())
+	}
+}
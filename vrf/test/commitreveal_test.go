@@ -0,0 +1,181 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf/commitreveal"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestCommitReveal(t *testing.T) {
+	players := []string{"carol", "alice", "bob"}
+	seeds := make(map[string][]byte)
+
+	round, err := commitreveal.NewRound("round-1", []byte("server-entropy-round-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range players {
+		seed, err := commitreveal.NewSeed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seeds[p] = seed
+
+		err = round.AddCommitment(p, commitreveal.Commit(round.ID(), p, seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// reveals are only accepted once the commit phase is over
+	if err := round.AddReveal("alice", seeds["alice"]); err == nil {
+		t.Fatal("expect a reveal during the commit phase to fail")
+	}
+
+	round.Close()
+	if err := round.AddCommitment("dave", commitreveal.Commit(round.ID(), "dave", seeds["alice"])); err == nil {
+		t.Fatal("expect a late commitment to fail")
+	}
+
+	if err := round.AddReveal("alice", seeds["bob"]); err == nil {
+		t.Fatal("expect a seed not matching the commitment to fail")
+	}
+
+	for _, p := range []string{"alice", "bob"} {
+		if err := round.AddReveal(p, seeds[p]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := round.Entropy(); err == nil {
+		t.Fatal("expect entropy to wait for carol")
+	}
+
+	if missing := round.Missing(); len(missing) != 1 || missing[0] != "carol" {
+		t.Fatalf("unexpected missing players %v", missing)
+	}
+
+	if err := round.AddReveal("carol", seeds["carol"]); err != nil {
+		t.Fatal(err)
+	}
+
+	entropy, err := round.Entropy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transcript, err := round.Transcript()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := commitreveal.VerifyTranscript(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(entropy, verified) {
+		t.Fatal("verified entropy doesn't match")
+	}
+
+	// the entropy feeds the VRF like any other entropy
+	ts := fixtureTipSets(t, 300, 300)[0]
+	vrfout, err := gamevrf.FilGenerateVRFByTipSet(gamevrf.DomainSeparationTag_GameRound, filPrivateKey, ts, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gamevrf.FilVerifyVRFByTipSet(gamevrf.DomainSeparationTag_GameRound, addr, ts, verified, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a server swapping a seed after the fact is caught
+	transcript.Reveals[0].Seed = seeds["bob"]
+	if _, err := commitreveal.VerifyTranscript(transcript); err == nil {
+		t.Fatal("expect a swapped seed to fail verification")
+	}
+}
+
+func TestCommitRevealForfeit(t *testing.T) {
+	seed, err := commitreveal.NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	round, err := commitreveal.NewRound("round-2", []byte("server-entropy-round-2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"alice", "bob"} {
+		if err := round.AddCommitment(p, commitreveal.Commit(round.ID(), p, seed)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	round.Close()
+	if err := round.AddReveal("bob", seed); err != nil {
+		t.Fatal(err)
+	}
+	if err := round.Forfeit("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript, err := round.Transcript()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transcript.Forfeits) != 1 || transcript.Forfeits[0] != "alice" {
+		t.Fatalf("unexpected forfeits %v", transcript.Forfeits)
+	}
+
+	if _, err := commitreveal.VerifyTranscript(transcript); err != nil {
+		t.Fatal(err)
+	}
+
+	// dropping a forfeit hides that alice committed
+	transcript.Forfeits = nil
+	if _, err := commitreveal.VerifyTranscript(transcript); err == nil {
+		t.Fatal("expect an unsettled commitment to fail verification")
+	}
+
+	// the same seed commits differently per player so commitments can't be copied
+	if bytes.Equal(commitreveal.Commit("round-2", "alice", seed), commitreveal.Commit("round-2", "bob", seed)) {
+		t.Fatal("commitments must be bound to the player")
+	}
+}
+
+func TestCommitRevealShortServerEntropy(t *testing.T) {
+	for _, serverEntropy := range [][]byte{nil, []byte("short")} {
+		_, err := commitreveal.NewRound("round-3", serverEntropy)
+		if err == nil {
+			t.Fatalf("expect server entropy of %d bytes to be rejected", len(serverEntropy))
+		}
+	}
+
+	// a transcript of a short server entropy doesn't verify even if it matches its commitment
+	serverEntropy := []byte("short")
+	transcript := &commitreveal.Transcript{
+		Version:          commitreveal.TRANSCRIPT_VERSION,
+		RoundID:          "round-3",
+		ServerCommitment: commitreveal.Commit("round-3", "", serverEntropy),
+		ServerEntropy:    serverEntropy,
+	}
+
+	_, err := commitreveal.VerifyTranscript(transcript)
+	if err == nil {
+		t.Fatal("expect a transcript with a short server entropy to be rejected")
+	}
+}
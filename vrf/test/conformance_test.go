@@ -0,0 +1,79 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/conformance"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+)
+
+// conformanceSuite is the suite shipped to the clients, regenerate it with
+// go run ./vrf/cmd/conformance -out vrf/test/testdata/conformance.json
+const conformanceSuite = "testdata/conformance.json"
+
+func TestConformanceSuite(t *testing.T) {
+	b, err := os.ReadFile(conformanceSuite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var committed conformance.Suite
+	err = json.Unmarshal(b, &committed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suite, err := conformance.Generate(committed.Outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// report the section that drifted before comparing the files
+	sections := []struct {
+		name              string
+		committed, actual interface{}
+	}{
+		{"Entropy", committed.Entropy, suite.Entropy},
+		{"VRF", committed.VRF, suite.VRF},
+		{"Derive", committed.Derive, suite.Derive},
+		{"Rng", committed.Rng, suite.Rng},
+	}
+	for _, s := range sections {
+		if !reflect.DeepEqual(s.committed, s.actual) {
+			t.Fatalf("%s vectors drifted from the committed suite", s.name)
+		}
+	}
+
+	actual, err := json.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(append(actual, '\n'), b) {
+		t.Fatal("committed suite isn't the output of vrf/cmd/conformance")
+	}
+
+	// the VRF vectors verify with the SDK, not only reproduce
+	for i, v := range committed.VRF {
+		pub, _ := hex.DecodeString(v.PublicKey)
+		rbase, _ := hex.DecodeString(v.RBase)
+		entropy, _ := hex.DecodeString(v.Entropy)
+		proof, _ := hex.DecodeString(v.Proof)
+
+		var height uint64
+		if err := json.Unmarshal([]byte(v.Height), &height); err != nil {
+			t.Fatal(err)
+		}
+
+		vrfout := &gamevrf.VRFOut{Height: height, Proof: proof}
+		err = gamevrf.VerifyVRF(pub, gamevrf.DomainSeparationTag(v.Tag), rbase, entropy, vrfout)
+		if err != nil {
+			t.Fatalf("VRF vector %d: %s", i, err)
+		}
+	}
+}
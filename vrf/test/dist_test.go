@@ -0,0 +1,184 @@
+package test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+)
+
+// moments returns the mean and variance of draws samples of f
+func moments(draws int, f func() float64) (mean, variance float64) {
+	var sum, sum2 float64
+	for i := 0; i < draws; i++ {
+		x := f()
+		sum += x
+		sum2 += x * x
+	}
+
+	mean = sum / float64(draws)
+	return mean, sum2/float64(draws) - mean*mean
+}
+
+func TestDistMoments(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest)
+	zipf, err := trand.NewZipf(2, 1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const draws = 200000
+	cases := []struct {
+		name           string
+		f              func() float64
+		mean, variance float64
+	}{
+		{"NormFloat64", func() float64 { return trand.NormFloat64(r) }, 0, 1},
+		{"ExpFloat64", func() float64 { return trand.ExpFloat64(r) }, 1, 1},
+		{"Poisson(3)", func() float64 { return float64(trand.Poisson(r, 3)) }, 3, 3},
+		{"Poisson(250)", func() float64 { return float64(trand.Poisson(r, 250)) }, 250, 250},
+		{"Binomial(20, 0.3)", func() float64 { return float64(trand.Binomial(r, 20, 0.3)) }, 6, 4.2},
+		{"Binomial(1000, 0.4)", func() float64 { return float64(trand.Binomial(r, 1000, 0.4)) }, 400, 240},
+		{"Binomial(1000, 0.9)", func() float64 { return float64(trand.Binomial(r, 1000, 0.9)) }, 900, 90},
+		{"Geometric(0.25)", func() float64 { return float64(trand.Geometric(r, 0.25)) }, 4, 12},
+	}
+
+	for _, c := range cases {
+		mean, variance := moments(draws, c.f)
+		sd := math.Sqrt(c.variance)
+		if math.Abs(mean-c.mean) > 5*sd/math.Sqrt(draws) {
+			t.Fatalf("%s: mean %f, expect %f", c.name, mean, c.mean)
+		}
+
+		if math.Abs(variance-c.variance) > 0.03*c.variance {
+			t.Fatalf("%s: variance %f, expect %f", c.name, variance, c.variance)
+		}
+	}
+
+	// P(k) is proportional to (1+k)^-2, about 0.6079 for k = 0 and 0.1520 for k = 1 with imax 1000
+	var counts [2]int
+	for i := 0; i < draws; i++ {
+		if k := zipf.Uint64(r); k < 2 {
+			counts[k]++
+		}
+	}
+	if p := float64(counts[0]) / draws; math.Abs(p-0.6079) > 0.005 {
+		t.Fatalf("Zipf P(0) %f", p)
+	}
+	if p := float64(counts[1]) / draws; math.Abs(p-0.1520) > 0.005 {
+		t.Fatalf("Zipf P(1) %f", p)
+	}
+}
+
+func TestDistBinomialPMF(t *testing.T) {
+	// BTPE draws must follow the binomial probabilities, not only its moments
+	r := trand.NewRng(trandSeed(), trand.RNGType_Cipher)
+
+	const n, p, draws = 100, 0.45, 100000
+	counts := make([]int, n+1)
+	for i := 0; i < draws; i++ {
+		counts[trand.Binomial(r, n, p)]++
+	}
+
+	var chi2 float64
+	bins := 0
+	for k := 30; k <= 60; k++ {
+		lc, _ := math.Lgamma(n + 1)
+		lk, _ := math.Lgamma(float64(k) + 1)
+		lnk, _ := math.Lgamma(float64(n-k) + 1)
+		expect := draws * math.Exp(lc-lk-lnk+float64(k)*math.Log(p)+float64(n-k)*math.Log(1-p))
+		d := float64(counts[k]) - expect
+		chi2 += d * d / expect
+		bins++
+	}
+
+	// 31 bins, 61.1 is about the 0.999 quantile of chi-square with 30 degrees of freedom
+	if chi2 > 61.1 {
+		t.Fatalf("binomial draws don't follow the distribution, chi2 %f over %d bins", chi2, bins)
+	}
+}
+
+func TestDistVectors(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Cipher)
+
+	got := []float64{
+		trand.NormFloat64(r),
+		trand.ExpFloat64(r),
+		float64(trand.Poisson(r, 4)),
+		float64(trand.Poisson(r, 40)),
+		float64(trand.Binomial(r, 10, 0.5)),
+		float64(trand.Binomial(r, 500, 0.2)),
+		float64(trand.Geometric(r, 0.1)),
+	}
+
+	expect := []float64{-0.3672202092743739, 1.1650227995528517, 7, 41, 7, 92, 1}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("unexpected draws %#v", got)
+	}
+}
+
+// TestDistGoldenVectors pins 16 draws of every distribution from a fresh generator, floats are compared
+// bit for bit so a compiler fusing multiply-adds on another architecture can't go unnoticed
+func TestDistGoldenVectors(t *testing.T) {
+	zipf, err := trand.NewZipf(1.2, 2, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	floatBits := func(f func(r trand.Rng) float64) func(r trand.Rng) uint64 {
+		return func(r trand.Rng) uint64 { return math.Float64bits(f(r)) }
+	}
+
+	vectors := []struct {
+		name   string
+		draw   func(r trand.Rng) uint64
+		expect []uint64
+	}{
+		{"NormFloat64", floatBits(trand.NormFloat64), []uint64{0xbfd780893150e0a7, 0xbfe474a2cec3145e, 0x3fd16aa9c8ded989, 0xbffaf6b00849a5ec, 0x40026f65e0be21e2, 0xbfcf2320e153a978, 0xbff492f9947b506e, 0xbfe3fa888ed5805b, 0x3fd6e36837f6afa8, 0x3fd0adb289cedec1, 0x3fec4e9f737b745f, 0xbf641aa8e4e84573, 0x3ff23cab59da24dc, 0xbfe2daba77e94268, 0x3fd019e52b4dce0a, 0x3fe816873035c980}},
+		{"ExpFloat64", floatBits(trand.ExpFloat64), []uint64{0x3fec03b7e25767e2, 0x3ff9ca678f73d7c3, 0x3ff2a3eef272c823, 0x3fcd3931537ffe26, 0x3ff743d2b097b2b2, 0x3f8392f31d50b061, 0x3fe18feb639535d4, 0x3fc6bc4b8b70a4f3, 0x3ff0c6859b78e0fe, 0x3fe0604dc8714a84, 0x3fdff2b696caa696, 0x3fe3e6ebe2b7dace, 0x4000ca0e77f7e2ec, 0x3fc52849a353ab9c, 0x3ff9980084897f2f, 0x3fc0b3ee8c011dba}},
+		{"Poisson(4)", func(r trand.Rng) uint64 { return uint64(trand.Poisson(r, 4)) }, []uint64{4, 7, 5, 3, 2, 3, 3, 4, 3, 7, 4, 3, 2, 4, 1, 3}},
+		{"Poisson(250.5)", func(r trand.Rng) uint64 { return uint64(trand.Poisson(r, 250.5)) }, []uint64{247, 242, 254, 243, 255, 229, 235, 292, 234, 234, 259, 257, 266, 252, 250, 264}},
+		{"Binomial(20, 0.3)", func(r trand.Rng) uint64 { return uint64(trand.Binomial(r, 20, 0.3)) }, []uint64{6, 4, 5, 8, 4, 11, 6, 8, 5, 6, 6, 6, 4, 8, 4, 8}},
+		{"Binomial(1000, 0.4)", func(r trand.Rng) uint64 { return uint64(trand.Binomial(r, 1000, 0.4)) }, []uint64{413, 389, 380, 400, 397, 411, 379, 382, 391, 377, 401, 430, 425, 398, 406, 419}},
+		{"Binomial(5000, 0.9)", func(r trand.Rng) uint64 { return uint64(trand.Binomial(r, 5000, 0.9)) }, []uint64{4483, 4515, 4528, 4500, 4504, 4486, 4529, 4525, 4513, 4531, 4499, 4460, 4467, 4504, 4492, 4475}},
+		{"Geometric(0.05)", func(r trand.Rng) uint64 { return uint64(trand.Geometric(r, 0.05)) }, []uint64{18, 32, 23, 5, 29, 1, 11, 4, 21, 10, 10, 13, 41, 4, 32, 3}},
+		{"Zipf(1.2, 2, 1000)", zipf.Uint64, []uint64{22, 117, 47, 1, 87, 0, 8, 1, 35, 6, 6, 10, 242, 1, 115, 0}},
+	}
+
+	for _, v := range vectors {
+		r := trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_V1)
+
+		got := make([]uint64, len(v.expect))
+		for i := range got {
+			got[i] = v.draw(r)
+		}
+
+		if !reflect.DeepEqual(got, v.expect) {
+			t.Fatalf("%s: unexpected draws %#v", v.name, got)
+		}
+	}
+}
+
+func TestDistInvalidArguments(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Normal)
+
+	for name, f := range map[string]func(){
+		"Poisson":   func() { trand.Poisson(r, -1) },
+		"Binomial":  func() { trand.Binomial(r, 10, 1.5) },
+		"Geometric": func() { trand.Geometric(r, 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expect %s to panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	if _, err := trand.NewZipf(1, 1, 10); err == nil {
+		t.Fatal("expect s <= 1 to fail")
+	}
+}
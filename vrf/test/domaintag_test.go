@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestTagRegistry(t *testing.T) {
+	reg := gamevrf.NewTagRegistry()
+
+	poker := reg.MustRegister("PokerDeal", gamevrf.DomainSeparationTag_GameDefinedMin)
+	if err := reg.Register("PokerDeal", gamevrf.DomainSeparationTag_GameDefinedMin+1); err == nil {
+		t.Fatal("expect a duplicate name to be rejected")
+	}
+	if err := reg.Register("PokerShuffle", poker); err == nil {
+		t.Fatal("expect a duplicate tag to be rejected")
+	}
+	if err := reg.Register("Low", gamevrf.DomainSeparationTag_GamePlayers+1); err == nil {
+		t.Fatal("expect a tag out of the game defined range to be rejected")
+	}
+	if err := reg.Register("GameBasic", poker+1); err == nil {
+		t.Fatal("expect a builtin name to be rejected")
+	}
+
+	if name, ok := reg.Name(poker); !ok || name != "PokerDeal" {
+		t.Fatalf("unexpected name %q", name)
+	}
+
+	if gamevrf.DomainSeparationTag_GameLottery.String() != "GameLottery" {
+		t.Fatalf("unexpected builtin name %s", gamevrf.DomainSeparationTag_GameLottery)
+	}
+
+	tag := gamevrf.DomainSeparationTag_GameDefinedMax
+	err := gamevrf.RegisterDomainSeparationTag("TestTagRegistryMax", tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"TestTagRegistryMax", tag.String(), "4294967295"} {
+		parsed, err := gamevrf.ParseDomainSeparationTag(s)
+		if err != nil || parsed != tag {
+			t.Fatalf("parse %q: %d %v", s, parsed, err)
+		}
+	}
+
+	parsed, err := gamevrf.ParseDomainSeparationTag(gamevrf.DomainSeparationTag(70000).String())
+	if err != nil || parsed != 70000 {
+		t.Fatalf("unregistered tag doesn't parse back: %d %v", parsed, err)
+	}
+}
+
+func TestTagRegistryOption(t *testing.T) {
+	m := newMockLotus(t, 2000)
+
+	slots := gamevrf.NewTagRegistry()
+	spin := slots.MustRegister("SlotsSpin", gamevrf.DomainSeparationTag_GameDefinedMin+100)
+
+	poker := gamevrf.NewTagRegistry()
+	deal := poker.MustRegister("PokerDeal", gamevrf.DomainSeparationTag_GameDefinedMin+200)
+
+	slotsVRF := gamevrf.NewWithProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), gamevrf.TagRegistryOption(slots))
+	pokerVRF := gamevrf.NewWithProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), gamevrf.TagRegistryOption(poker))
+
+	ctx := context.Background()
+	entropy := []byte("tag-entropy")
+	vrfout, err := slotsVRF.GenerateVRFContext(ctx, spin, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = slotsVRF.GenerateVRFContext(ctx, deal, filPrivateKey, entropy)
+	if err == nil {
+		t.Fatal("expect the slots game to reject the poker tag")
+	}
+
+	_, err = slotsVRF.GenerateVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+	if err == nil {
+		t.Fatal("expect unregistered builtin tags to be rejected")
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = slotsVRF.VerifyVRFContext(ctx, spin, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = pokerVRF.VerifyVRFContext(ctx, spin, addr, entropy, vrfout)
+	if err == nil {
+		t.Fatal("expect the poker game to reject a slots VRF")
+	}
+}
@@ -0,0 +1,263 @@
+package test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+const (
+	// drandSyntheticFixture holds two SYNTHETIC drand chains signed with generated group keys, an unchained G1 chain
+	// like quicknet and a chained G2 chain like the drand default chain, in the format of the drand HTTP API.
+	// Their beacon ids are prefixed with synthetic- so their chain hashes aren't mistaken for real chains.
+	drandSyntheticFixture = "testdata/drand_synthetic_fixture.json"
+	// drandMainnetFixture holds the chain info and beacons of the drand mainnet quicknet and default chains,
+	// recorded from https://api.drand.sh/<chain hash>/info and https://api.drand.sh/<chain hash>/public/<round>
+	drandMainnetFixture = "testdata/drand_mainnet_fixture.json"
+)
+
+// drandMainnetChains are the hashes and the randomness of the first beacon of the chains in drandMainnetFixture
+var drandMainnetChains = []struct {
+	hash       string
+	randomness string
+}{
+	{"52db9ba70e0cc0f6eaf7803dd07447a1f5477735fd3f661792ba94600c84e971", "fe290beca10872ef2fb164d2aa4442de4566183ec51c56ff3cd603d930e54fdd"},
+	{"8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce", "101297f1ca7dc44ef6088d94ad5fb7ba03455dc33d53ddb412bbc4564ed986ec"},
+}
+
+type drandChain struct {
+	Info    *gamevrf.DrandChainInfo
+	Beacons []*gamevrf.DrandBeacon
+}
+
+func loadDrandFixture(t *testing.T, path string) (unchained, chained *drandChain) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixture struct {
+		Chains []*drandChain
+	}
+	err = json.Unmarshal(b, &fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fixture.Chains[0], fixture.Chains[1]
+}
+
+// newDrandServer serves chain like the drand HTTP API, the latest round is the last beacon
+func newDrandServer(t *testing.T, chain *drandChain) *httptest.Server {
+	hash := hex.EncodeToString(chain.Info.Hash)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/"+hash+"/")
+		if path == "info" {
+			_ = json.NewEncoder(w).Encode(chain.Info)
+			return
+		}
+
+		round := strings.TrimPrefix(path, "public/")
+		for i, b := range chain.Beacons {
+			if round == strconv.FormatUint(b.Round, 10) || (round == "latest" && i == len(chain.Beacons)-1) {
+				_ = json.NewEncoder(w).Encode(b)
+				return
+			}
+		}
+
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestDrandVerifyBeacon(t *testing.T) {
+	quicknet, chained := loadDrandFixture(t, drandSyntheticFixture)
+
+	for _, chain := range []*drandChain{quicknet, chained} {
+		for _, b := range chain.Beacons {
+			err := chain.Info.VerifyBeacon(b)
+			if err != nil {
+				t.Fatalf("%s: %s", chain.Info.Scheme, err)
+			}
+		}
+
+		forged := *chain.Beacons[0]
+		forged.Round++
+		if chain.Info.VerifyBeacon(&forged) == nil {
+			t.Fatalf("%s: expect a beacon of another round to fail", chain.Info.Scheme)
+		}
+	}
+
+	// a chained beacon can't be verified without the previous signature
+	b := *chained.Beacons[1]
+	b.PreviousSignature = nil
+	if chained.Info.VerifyBeacon(&b) == nil {
+		t.Fatal("expect a chained beacon without previous signature to fail")
+	}
+
+	if quicknet.Info.RoundAt(quicknet.Info.RoundTime(1005)) != 1005 {
+		t.Fatal("RoundAt doesn't invert RoundTime")
+	}
+}
+
+func TestDrandMainnetChains(t *testing.T) {
+	quicknet, chained := loadDrandFixture(t, drandMainnetFixture)
+
+	for i, chain := range []*drandChain{quicknet, chained} {
+		if hex.EncodeToString(chain.Info.Hash) != drandMainnetChains[i].hash {
+			t.Fatalf("%s: unexpected chain hash %x", chain.Info.BeaconID, chain.Info.Hash)
+		}
+
+		err := chain.Info.VerifyHash()
+		if err != nil {
+			t.Fatalf("%s: %s", chain.Info.BeaconID, err)
+		}
+
+		b := chain.Beacons[0]
+		err = chain.Info.VerifyBeacon(b)
+		if err != nil {
+			t.Fatalf("%s: %s", chain.Info.BeaconID, err)
+		}
+
+		r := b.Randomness()
+		if hex.EncodeToString(r[:]) != drandMainnetChains[i].randomness {
+			t.Fatalf("%s: unexpected randomness %x", chain.Info.BeaconID, r)
+		}
+
+		forged := *chain.Info
+		forged.PublicKey = quicknet.Info.PublicKey
+		forged.BeaconID = "other"
+		if forged.VerifyHash() == nil {
+			t.Fatalf("%s: expect the chain hash to bind the chain info", chain.Info.BeaconID)
+		}
+	}
+
+	if quicknet.Info.Scheme != gamevrf.DRAND_SCHEME_UNCHAINED_G1 || chained.Info.Scheme != gamevrf.DRAND_SCHEME_CHAINED {
+		t.Fatalf("unexpected schemes %s %s", quicknet.Info.Scheme, chained.Info.Scheme)
+	}
+}
+
+func TestDrandGameVRF(t *testing.T) {
+	quicknet, chained := loadDrandFixture(t, drandSyntheticFixture)
+	realQuicknet, realChained := loadDrandFixture(t, drandMainnetFixture)
+	m := newMockLotus(t, 2000)
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, chain := range []*drandChain{quicknet, chained, realQuicknet, realChained} {
+		srv := newDrandServer(t, chain)
+		source := gamevrf.NewDrandHTTPSource(srv.URL, hex.EncodeToString(chain.Info.Hash), nil)
+
+		info, err := source.ChainInfo(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gg := gamevrf.NewWithProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), gamevrf.DrandBaseOption(info, source))
+
+		entropy := []byte("drand-entropy")
+		vrfout, meta, err := gg.GenerateVRFWithMeta(ctx, gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		latest := chain.Beacons[len(chain.Beacons)-1]
+		if vrfout.BaseKind != gamevrf.BaseKind_Drand || vrfout.Height != latest.Round || meta.BaseHeight != latest.Round {
+			t.Fatalf("%s: unexpected vrf base %s %d", chain.Info.Scheme, vrfout.BaseKind, vrfout.Height)
+		}
+
+		err = gg.VerifyVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		receipt, err := gg.ReceiptContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := gamevrf.MarshalReceiptJSON(receipt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := gamevrf.UnmarshalReceiptJSON(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = gamevrf.VerifyDrandReceipt(info, decoded, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = gamevrf.VerifyReceiptOnline(ctx, gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), decoded, nil)
+		if err == nil {
+			t.Fatal("expect drand receipts to be rejected by VerifyReceiptOnline")
+		}
+
+		// min ticket VRFs still verify with the drand base configured
+		ticketVRF, err := gamevrf.New(filrpc.NodeURLOption(m.URL())).GenerateVRF(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = gg.VerifyVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, ticketVRF)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDrandTipSetBeaconSource(t *testing.T) {
+	quicknet, _ := loadDrandFixture(t, drandSyntheticFixture)
+
+	// with the filecoin genesis at the drand genesis, epoch e includes round 10*(e-1)+1 of a 3s chain
+	tipsets := fixtureTipSets(t, 99, 103)
+	tipsets[2].Blocks()[0].BeaconEntries = []filrpc.BeaconEntry{{Round: 1001, Data: quicknet.Beacons[1].Signature}}
+	p := gamevrf.NewMemoryProvider(tipsets...)
+
+	source := gamevrf.NewTipSetBeaconSource(p, quicknet.Info, quicknet.Info.GenesisTime)
+	ctx := context.Background()
+
+	b, err := source.Beacon(ctx, 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := quicknet.Info.VerifyBeacon(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// heads 102 and 103 have no entries, the latest beacon is found below them
+	latest, err := source.Beacon(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if latest.Round != 1001 {
+		t.Fatalf("latest round %d != 1001", latest.Round)
+	}
+
+	_, err = source.Beacon(ctx, 1002)
+	if err == nil {
+		t.Fatal("expect a round not on chain to fail")
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	api "github.com/Filecoin-Titan/titan-game-sdk/contracts/api"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf/entropy"
+)
+
+func TestEntropyCanonical(t *testing.T) {
+	a, err := entropy.NewRound("abc-efg-hi", "round-1", "replay-1", "b", "a", "c").
+		AddUint("bet", 100).
+		AddString("table", "t1").
+		Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := entropy.NewRound("abc-efg-hi", "round-1", "replay-1", "c", "b", "a").
+		AddString("table", "t1").
+		AddUint("bet", 100).
+		Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(a, b) {
+		t.Fatal("player and field order changed the entropy")
+	}
+
+	// pins version 1 of the encoding, changing it breaks the verification of past rounds
+	expect := "01" +
+		"0000000a" + hex.EncodeToString([]byte("abc-efg-hi")) +
+		"00000007" + hex.EncodeToString([]byte("round-1")) +
+		"00000008" + hex.EncodeToString([]byte("replay-1")) +
+		"00000003" + "0000000161" + "0000000162" + "0000000163" +
+		"00000002" +
+		"00000003" + hex.EncodeToString([]byte("bet")) + "03" + "00000008" + "0000000000000064" +
+		"00000005" + hex.EncodeToString([]byte("table")) + "01" + "00000002" + hex.EncodeToString([]byte("t1"))
+	if hex.EncodeToString(a) != expect {
+		t.Fatalf("unexpected encoding %x", a)
+	}
+
+	// a player or field given twice is most likely a bug of the caller
+	_, err = entropy.NewRound("g", "r", "p", "a", "a").Encode()
+	if err == nil {
+		t.Fatal("expect duplicate players to be rejected")
+	}
+
+	_, err = entropy.NewRound("g", "r", "p").AddUint("x", 1).AddBool("x", true).Encode()
+	if err == nil {
+		t.Fatal("expect duplicate fields to be rejected")
+	}
+}
+
+func TestEntropyDecode(t *testing.T) {
+	round := entropy.NewRound("game", "round", "replay", "p2", "p1").
+		AddInt("seat", -3).
+		AddBool("ranked", true).
+		AddBytes("salt", []byte{1, 2, 3})
+
+	b, err := round.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := entropy.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := decoded.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(b, again) {
+		t.Fatal("decoded round doesn't encode back to the same entropy")
+	}
+
+	seat, ok := decoded.Field("seat")
+	if !ok {
+		t.Fatal("missing seat field")
+	}
+
+	if v, err := seat.Int(); err != nil || v != -3 {
+		t.Fatalf("seat %d %v", v, err)
+	}
+
+	_, err = entropy.Decode(append(b, 0))
+	if err == nil {
+		t.Fatal("expect trailing bytes to be rejected")
+	}
+
+	_, err = entropy.Decode(b[:len(b)-1])
+	if err == nil {
+		t.Fatal("expect truncated entropy to be rejected")
+	}
+}
+
+func TestEntropyFromChain(t *testing.T) {
+	round := entropy.NewRound("game", "round", "replay", "bob", "alice")
+	expect, err := round.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	onchain := api.GameRoundInfo(round.GameRoundInfo())
+	if onchain.PlayerIDs != "alice,bob" {
+		t.Fatalf("unexpected on chain players %s", onchain.PlayerIDs)
+	}
+
+	rebuilt, err := entropy.FromGameRoundInfo(entropy.GameRoundInfo(onchain)).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(rebuilt, expect) {
+		t.Fatal("entropy rebuilt from chain doesn't match")
+	}
+}
+
+func TestEntropyPlayerSeparator(t *testing.T) {
+	// "a,b" + "c" and "a" + "b,c" store the same players on chain
+	for _, players := range [][]string{{"a,b", "c"}, {"a", "b,c"}, {"a", ""}} {
+		_, err := entropy.NewRound("game", "round", "replay", players...).Encode()
+		if err == nil {
+			t.Fatalf("expect players %q to be rejected", players)
+		}
+	}
+
+	_, err := entropy.FromGameRoundInfo(entropy.GameRoundInfo{GameID: "game", PlayerIDs: "a,,b"}).Encode()
+	if err == nil {
+		t.Fatal("expect an empty player stored on chain to be rejected")
+	}
+
+	// a non canonical encoding carrying a separator
+	b := []byte{entropy.ENTROPY_VERSION, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 3, 'a', ',', 'b', 0, 0, 0, 0}
+	_, err = entropy.Decode(b)
+	if err == nil {
+		t.Fatal("expect a player containing the separator to be rejected")
+	}
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+type countingTransport struct {
+	count int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.count, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientContextCancel(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	m.SetDelay(time.Second)
+
+	client := filrpc.New(filrpc.NodeURLOption(m.URL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.ChainHeadContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("request was not canceled with ctx")
+	}
+}
+
+func TestClientTimeoutOption(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	m.SetDelay(time.Second)
+
+	client := filrpc.New(
+		filrpc.NodeURLOption(m.URL()),
+		filrpc.TimeoutOption(50*time.Millisecond),
+	)
+
+	_, err := client.ChainGetTipSetByHeight(900)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestClientHTTPClientOption(t *testing.T) {
+	m := newMockLotus(t, 1000)
+
+	transport := &countingTransport{}
+	client := filrpc.New(
+		filrpc.NodeURLOption(m.URL()),
+		filrpc.HTTPClientOption(&http.Client{Transport: transport}),
+	)
+
+	ts, err := client.ChainGetTipSetByHeightContext(context.Background(), 900)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ts.Height() != 900 {
+		t.Fatalf("tipset height %d != 900", ts.Height())
+	}
+
+	if atomic.LoadInt32(&transport.count) != 1 {
+		t.Fatalf("custom http client was not used")
+	}
+}
+
+func TestGameVRFContext(t *testing.T) {
+	m := newMockLotus(t, 1000)
+
+	gg := gamevrf.New(filrpc.NodeURLOption(m.URL()))
+
+	entropy := []byte("game-round-entropy")
+	vrfout, err := gg.GenerateVRFContext(context.Background(), gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vrfout.Height != 1000-gamevrf.GAME_CHAIN_EPOCH_LOOKBACK {
+		t.Fatalf("vrf height %d != %d", vrfout.Height, 1000-gamevrf.GAME_CHAIN_EPOCH_LOOKBACK)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gg.VerifyVRFContext(context.Background(), gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = gg.VerifyVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect context.Canceled, got %v", err)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	m.SetToken("lotus-jwt")
+
+	_, err := filrpc.New(filrpc.NodeURLOption(m.URL())).ChainHead()
+	var httpErr *filrpc.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expect a 401 HTTPError, got %v", err)
+	}
+
+	client := filrpc.New(filrpc.NodeURLOption(m.URL()), filrpc.TokenOption("lotus-jwt"))
+	if _, err := client.ChainHead(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	m := newMockLotus(t, 1000)
+
+	transport := &countingTransport{}
+	client := filrpc.New(
+		filrpc.NodeURLOption(m.URL()),
+		filrpc.TransportOption(transport),
+		filrpc.RetryOption(2, time.Millisecond, 10*time.Millisecond),
+	)
+
+	m.FailNext(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	ts, err := client.ChainGetTipSetByHeight(900)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Height() != 900 {
+		t.Fatalf("tipset height %d != 900", ts.Height())
+	}
+	if atomic.LoadInt32(&transport.count) != 3 {
+		t.Fatalf("expect 3 attempts, got %d", transport.count)
+	}
+
+	// retries are exhausted
+	m.FailNext(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	_, err = client.ChainHead()
+	var httpErr *filrpc.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expect a 502 HTTPError, got %v", err)
+	}
+
+	// other statuses aren't retried
+	m.FailNext(http.StatusBadRequest)
+	before := atomic.LoadInt32(&transport.count)
+	_, err = client.ChainHead()
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect a 400 HTTPError, got %v", err)
+	}
+	if atomic.LoadInt32(&transport.count)-before != 1 {
+		t.Fatal("expect a 400 response not to be retried")
+	}
+}
+
+func TestClientRetryContext(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	client := filrpc.New(filrpc.NodeURLOption(m.URL()), filrpc.RetryOption(5, time.Second, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	m.FailNext(http.StatusServiceUnavailable)
+	_, err := client.ChainHeadContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect context.DeadlineExceeded during the backoff, got %v", err)
+	}
+}
+
+func TestClientRPCError(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	client := filrpc.New(filrpc.NodeURLOption(m.URL()))
+
+	_, err := client.StateMinerInfoContext(context.Background(), address.Undef, nil)
+	var rpcErr *filrpc.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Fatalf("expect an RPCError -32601, got %v", err)
+	}
+}
+
+func TestClientRequestIDs(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	client := filrpc.New(filrpc.NodeURLOption(m.URL()))
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.ChainHead(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range m.IDs() {
+		if seen[id] {
+			t.Fatalf("request id %s sent twice", id)
+		}
+		seen[id] = true
+	}
+}
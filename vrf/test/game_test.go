@@ -0,0 +1,171 @@
+package test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+)
+
+// TestGameVectors pins the outputs of the game helpers for trandSeed, implementations
+// in other languages must draw the same values in the same order
+func TestGameVectors(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Cipher)
+
+	perm := trand.Perm(r, 10)
+	if !reflect.DeepEqual(perm, []int{6, 0, 3, 7, 9, 8, 5, 2, 1, 4}) {
+		t.Fatalf("unexpected Perm %v", perm)
+	}
+
+	sample, err := trand.SampleWithoutReplacement(r, []string{"a", "b", "c", "d", "e", "f"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sample, []string{"d", "e", "b"}) {
+		t.Fatalf("unexpected SampleWithoutReplacement %v", sample)
+	}
+
+	alias, err := trand.NewAlias([]uint64{1, 0, 5, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var picks []int
+	for i := 0; i < 10; i++ {
+		picks = append(picks, alias.Choose(r))
+	}
+	if !reflect.DeepEqual(picks, []int{2, 2, 2, 2, 2, 0, 2, 2, 2, 2}) {
+		t.Fatalf("unexpected Choose %v", picks)
+	}
+
+	dice, err := trand.RollDice(r, 5, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dice, []int{3, 1, 5, 3, 3}) {
+		t.Fatalf("unexpected RollDice %v", dice)
+	}
+
+	hands, err := trand.NewDeck(r, trand.StandardDeck()).DealHands(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(hands) != "[[Ad 9d] [6s Js]]" {
+		t.Fatalf("unexpected DealHands %v", hands)
+	}
+}
+
+func TestGameWeightedChoice(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Normal)
+
+	weights := []uint64{1, 0, 5, 2, 2}
+	alias, err := trand.NewAlias(weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const draws = 100000
+	counts := make([]int, len(weights))
+	for i := 0; i < draws; i++ {
+		counts[alias.Choose(r)]++
+	}
+
+	if counts[1] != 0 {
+		t.Fatal("a zero weight was picked")
+	}
+
+	for i, w := range weights {
+		expect := float64(draws) * float64(w) / 10
+		if d := float64(counts[i]) - expect; d > 0.02*draws || d < -0.02*draws {
+			t.Fatalf("index %d picked %d times, expect about %f", i, counts[i], expect)
+		}
+	}
+
+	for _, weights := range [][]uint64{nil, {0, 0}, {1 << 63, 1 << 63}, {1 << 62, 1 << 62, 1}} {
+		if _, err := trand.WeightedChoice(r, weights); err == nil {
+			t.Fatalf("expect weights %v to fail", weights)
+		}
+	}
+}
+
+func TestGameDeck(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest)
+
+	cards := trand.StandardDeck()
+	deck := trand.NewDeck(r, cards)
+
+	hands, err := deck.DealHands(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rest, err := deck.Deal(deck.Remaining())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[trand.Card]bool)
+	for _, hand := range append(hands, rest) {
+		for _, c := range hand {
+			if seen[c] {
+				t.Fatalf("card %s dealt twice", c)
+			}
+			seen[c] = true
+		}
+	}
+
+	if len(seen) != 52 || cards[0].String() != "2c" || cards[51].String() != "As" {
+		t.Fatal("deck isn't a standard deck")
+	}
+
+	if _, err := deck.Deal(1); err == nil {
+		t.Fatal("expect dealing from an empty deck to fail")
+	}
+
+	if _, err := trand.SampleWithoutReplacement(r, cards, 53); err == nil {
+		t.Fatal("expect sampling more than the population to fail")
+	}
+
+	if _, err := trand.RollDice(r, 2, 0); err == nil {
+		t.Fatal("expect dice without sides to fail")
+	}
+}
+
+// plainRng hides the RngV1 methods of a generator, like an Rng implemented outside the package
+type plainRng struct {
+	r trand.Rng
+}
+
+func (p plainRng) Intn(n int) int   { return p.r.Intn(n) }
+func (p plainRng) Uint64() uint64   { return p.r.Uint64() }
+func (p plainRng) Float64() float64 { return p.r.Float64() }
+
+func TestGamePlainRng(t *testing.T) {
+	// the helpers draw the same values from an Rng as from the RngV1 it wraps
+	v1 := trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_V1)
+	plain := plainRng{trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_V1)}
+
+	if _, ok := trand.Rng(plain).(trand.RngV1); ok {
+		t.Fatal("plainRng must only implement Rng")
+	}
+
+	if a, b := trand.Perm(v1, 20), trand.Perm(plain, 20); !reflect.DeepEqual(a, b) {
+		t.Fatalf("Perm %v != %v", a, b)
+	}
+
+	a, err := trand.RollDice(v1, 10, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := trand.RollDice(plain, 10, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("RollDice %v != %v", a, b)
+	}
+
+	if a, b := trand.NormFloat64(v1), trand.NormFloat64(plain); a != b {
+		t.Fatalf("NormFloat64 %v != %v", a, b)
+	}
+}
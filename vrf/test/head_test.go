@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+)
+
+// waitHead waits for the tracker to follow the chain head to height
+func waitHead(t *testing.T, tracker *gamevrf.HeadTracker, height uint64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		ts, err := tracker.ChainHead(context.Background())
+		if err == nil && ts.Height() == height {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("tracker didn't reach height %d", height)
+}
+
+// waitSubscribed waits for the tracker subscription to be subscribed
+func waitSubscribed(t *testing.T, tracker *gamevrf.HeadTracker, subscribed bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if tracker.Subscribed() == subscribed {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("tracker subscribed %v, expect %v", !subscribed, subscribed)
+}
+
+func TestChainNotify(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	client := filrpc.New(filrpc.NodeURLOption(m.URL()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := client.ChainNotify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := <-ch
+	if len(changes) != 1 || changes[0].Type != filrpc.HCCurrent || changes[0].Val.Height() != 1000 {
+		t.Fatalf("unexpected first notification %v", changes)
+	}
+
+	m.SetHead(1001)
+	changes = <-ch
+	if len(changes) != 1 || changes[0].Type != filrpc.HCApply || changes[0].Val.Height() != 1001 {
+		t.Fatalf("unexpected notification %v", changes)
+	}
+
+	cancel()
+	for range ch {
+	}
+
+	m.SetWebsocket(false)
+	if _, err := client.ChainNotify(context.Background()); err == nil {
+		t.Fatal("expect the subscription to fail without websocket")
+	}
+}
+
+func TestHeadTrackerSubscription(t *testing.T) {
+	m := newMockLotus(t, 1000)
+
+	// a poll interval long enough for the head to come from the subscription only
+	tracker := gamevrf.NewHeadTracker(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), time.Hour)
+	tracker.Start()
+	defer tracker.Close()
+
+	waitSubscribed(t, tracker, true)
+	waitHead(t, tracker, 1000)
+
+	m.SetHead(1003) // two null rounds
+	waitHead(t, tracker, 1003)
+
+	gg := gamevrf.NewWithProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), gamevrf.HeadTrackerOption(tracker))
+	_, meta, err := gg.GenerateVRFWithMeta(context.Background(), gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, []byte("entropy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.GameEpoch != 1003 {
+		t.Fatalf("game epoch %d != 1003", meta.GameEpoch)
+	}
+}
+
+func TestHeadTrackerPolling(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	m.SetWebsocket(false)
+
+	tracker := gamevrf.NewHeadTracker(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), 20*time.Millisecond)
+	tracker.Start()
+	defer tracker.Close()
+
+	m.SetHead(1002)
+	waitHead(t, tracker, 1002)
+	if tracker.Subscribed() || tracker.Err() != nil {
+		t.Fatalf("expect the tracker to poll, subscribed %v err %v", tracker.Subscribed(), tracker.Err())
+	}
+
+	// the tracker subscribes once websockets are back
+	m.SetWebsocket(true)
+	waitSubscribed(t, tracker, true)
+
+	m.SetHead(1004)
+	waitHead(t, tracker, 1004)
+
+	// and polls again when the subscription is lost
+	m.SetWebsocket(false)
+	waitSubscribed(t, tracker, false)
+
+	m.SetHead(1005)
+	waitHead(t, tracker, 1005)
+
+	m.SetWebsocket(true)
+	waitSubscribed(t, tracker, true)
+}
+
+func TestHeadTrackerStalled(t *testing.T) {
+	m := newMockLotus(t, 1000)
+
+	tracker := gamevrf.NewHeadTracker(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), time.Hour,
+		gamevrf.TrackerMaxAgeOption(50*time.Millisecond))
+	tracker.Start()
+	defer tracker.Close()
+
+	waitSubscribed(t, tracker, true)
+	waitHead(t, tracker, 1000)
+
+	// a subscription pushing no heads isn't trusted past the max age
+	m.SetStalled(true)
+	m.SetHead(1002)
+	waitHead(t, tracker, 1002)
+	if !tracker.Subscribed() {
+		t.Fatal("expect the stalled subscription to be kept")
+	}
+}
+
+func TestHeadTrackerRestart(t *testing.T) {
+	m := newMockLotus(t, 1000)
+
+	tracker := gamevrf.NewHeadTracker(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), time.Hour)
+	tracker.Start()
+	waitSubscribed(t, tracker, true)
+
+	tracker.Close()
+	if tracker.Subscribed() {
+		t.Fatal("expect no subscription once closed")
+	}
+
+	// a closed tracker follows the head again once started
+	tracker.Start()
+	defer tracker.Close()
+
+	waitSubscribed(t, tracker, true)
+	m.SetHead(1001)
+	waitHead(t, tracker, 1001)
+}
+
+func TestHeadTrackerNotStarted(t *testing.T) {
+	m := newMockLotus(t, 1000)
+	tracker := gamevrf.NewHeadTracker(gamevrf.NewMemoryProvider(m.TipSet(990), m.TipSet(1000)), 0)
+
+	ts, err := tracker.ChainHead(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ts.Height() != 1000 {
+		t.Fatalf("head %d != 1000", ts.Height())
+	}
+
+	tracker.Close()
+}
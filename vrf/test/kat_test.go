@@ -0,0 +1,141 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// TestKATPCG64DXSM checks the vectors of TestPCG in math/rand/v2, the generator seeded with NewPCG(1, 2)
+func TestKATPCG64DXSM(t *testing.T) {
+	var seed [32]byte
+	binary.BigEndian.PutUint64(seed[0:], 1)
+	binary.BigEndian.PutUint64(seed[8:], 2)
+
+	want := []uint64{
+		0xc4f5a58656eef510, 0x9dcec3ad077dec6c, 0xc8d04605312f8088, 0xcbedc0dcb63ac19a,
+		0x3bf98798cae97950, 0xa8c6d7f8d485abc, 0x7ffa3780429cd279, 0x730ad2626b1c2f8e,
+		0x21ff2330f4a0ad99, 0x2f0901a1947094b0, 0xa9735a3cfbe36cef, 0x71ddb0a01a12c84a,
+		0xf0e53e77a78453bb, 0x1f173e9663be1e9d, 0x657651da3ac4115e, 0xc8987376b65a157b,
+		0xbb17008f5fca28e7, 0x8232bd645f29ed22, 0x12be8f07ad14c539, 0x54908a48e8e4736e,
+	}
+
+	r := trand.NewRng(seed, trand.RNGType_PCG64DXSM)
+	for i, x := range want {
+		if u := r.Uint64(); u != x {
+			t.Fatalf("PCG #%d = %#x, want %#x", i, u, x)
+		}
+	}
+}
+
+// TestKATChaCha20 checks the test vector #1 of RFC 8439 appendix A.1, the keystream of the
+// zero key and nonce, and the following blocks against golang.org/x/crypto/chacha20
+func TestKATChaCha20(t *testing.T) {
+	rfc8439, _ := hex.DecodeString("76b8e0ada0f13d90405d6ae55386bd28bdd219b8a08ded1aa836efcc8b770dc7" +
+		"da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586")
+
+	var seed [32]byte
+	r := trand.NewRng(seed, trand.RNGType_ChaCha20)
+
+	stream := make([]byte, 64*100)
+	for i := 0; i < len(stream); i += 8 {
+		binary.LittleEndian.PutUint64(stream[i:], r.Uint64())
+	}
+
+	if !bytes.Equal(stream[:64], rfc8439) {
+		t.Fatalf("first block %x, want %x", stream[:64], rfc8439)
+	}
+
+	c, err := chacha20.NewUnauthenticatedCipher(seed[:], make([]byte, chacha20.NonceSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := make([]byte, len(stream))
+	c.XORKeyStream(expect, expect)
+	if !bytes.Equal(stream, expect) {
+		t.Fatal("keystream doesn't match golang.org/x/crypto/chacha20")
+	}
+}
+
+// TestKATCtrDRBG checks the CTR_DRBG self test of the Go FIPS 140-3 module (crypto/internal/fips140/drbg):
+// instantiate, reseed with additional input, then generate with additional input
+func TestKATCtrDRBG(t *testing.T) {
+	seq := func(from byte) []byte {
+		b := make([]byte, trand.CTR_DRBG_SEED_SIZE)
+		for i := range b {
+			b[i] = from + byte(i)
+		}
+		return b
+	}
+
+	want, _ := hex.DecodeString("6e6e479d24f86a3b7787a8f8186d985a53bebeeddeab9228f0f4ac6e10bf0193")
+
+	d, err := trand.NewCtrDRBG(seq(0x01), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Reseed(seq(0x31), seq(0x61)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]byte, len(want))
+	if err := d.Generate(got, seq(0x61)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Fatalf("CTR_DRBG output %x, want %x", got, want)
+	}
+
+	if _, err := trand.NewCtrDRBG(seq(0x01)[1:], nil); err == nil {
+		t.Fatal("expect a short entropy input to fail")
+	}
+
+	if err := d.Generate(make([]byte, trand.CTR_DRBG_MAX_REQUEST+1), nil); err == nil {
+		t.Fatal("expect an oversized request to fail")
+	}
+}
+
+func TestKATSnapshots(t *testing.T) {
+	for _, typ := range []trand.RNGType{trand.RNGType_PCG64DXSM, trand.RNGType_ChaCha20, trand.RNGType_CtrDRBG} {
+		r := trand.NewRng(trandSeed(), typ, trand.AlgorithmVersion_Latest)
+		for i := 0; i < 13; i++ {
+			r.Uint64()
+		}
+
+		snapshot, err := r.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restored, err := trand.UnmarshalRng(snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			if a, b := r.Uint64(), restored.Uint64(); a != b {
+				t.Fatalf("%s: draw %d after restore %x != %x", typ, i, b, a)
+			}
+		}
+	}
+
+	r := trand.NewRng(trandSeed(), trand.RNGType_ChaCha20)
+	var draws []uint64
+	for i := 0; i < 20; i++ {
+		draws = append(draws, r.Uint64())
+	}
+
+	seeker := trand.NewRng(trandSeed(), trand.RNGType_ChaCha20).(trand.Seeker)
+	seeker.SeekTo(13)
+	if seeker.(trand.Rng).Uint64() != draws[13] {
+		t.Fatal("ChaCha20 seek doesn't match")
+	}
+}
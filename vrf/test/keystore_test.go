@@ -0,0 +1,229 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf/keystore"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	key, err := keystore.NewKey(gamevrf.KTBLS, filPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	password := []byte("correct horse battery staple")
+	err = keystore.WriteFile(path, key, password, keystore.LightScryptParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := keystore.ReadFile(path, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(loaded.PrivateKey, filPrivateKey) {
+		t.Fatal("decrypted key doesn't match")
+	}
+
+	pub, err := loaded.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(pub, filPublicKey) {
+		t.Fatal("public key doesn't match")
+	}
+
+	_, err = keystore.ReadFile(path, []byte("wrong password"))
+	if err == nil {
+		t.Fatal("expect a wrong password to fail")
+	}
+}
+
+func TestKeystoreLotusExport(t *testing.T) {
+	key, err := keystore.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := key.ExportLotus()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv, err := gamevrf.FilBlsKeyFromString(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := keystore.ImportLotus(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(priv, key.PrivateKey) || !bytes.Equal(imported.PrivateKey, key.PrivateKey) {
+		t.Fatal("exported key doesn't import back")
+	}
+
+	// the generated key must produce VRFs verifiable with its address
+	addr, err := key.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := fixtureTipSets(t, 100, 100)[0]
+	entropy := []byte("keystore-entropy")
+	vrfout, err := gamevrf.FilGenerateVRFByTipSet(gamevrf.DomainSeparationTag_GameBasic, key.PrivateKey, ts, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gamevrf.FilVerifyVRFByTipSet(gamevrf.DomainSeparationTag_GameBasic, addr, ts, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// secp256k1 keys can't generate VRFs
+	b, _ := json.Marshal(gamevrf.KeyInfo{Type: "secp256k1", PrivateKey: key.PrivateKey})
+	_, err = keystore.ImportLotus(hex.EncodeToString(b))
+	if err == nil {
+		t.Fatal("expect secp256k1 keys to be rejected")
+	}
+
+	_, err = gamevrf.FilBlsKeyFromString(hex.EncodeToString(b))
+	if err == nil {
+		t.Fatal("expect secp256k1 keys to be rejected")
+	}
+}
+
+func TestKeystoreAddressString(t *testing.T) {
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f3, err := keystore.AddressString(addr, address.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t3, err := keystore.AddressString(addr, address.Testnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(f3, "f3") || !strings.HasPrefix(t3, "t3") || f3[1:] != t3[1:] {
+		t.Fatalf("unexpected addresses %s %s", f3, t3)
+	}
+
+	parsed, err := address.NewFromString(f3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed != addr {
+		t.Fatal("f3 address doesn't parse back")
+	}
+}
+
+func TestKeystoreCorrupted(t *testing.T) {
+	key, err := keystore.NewKey(gamevrf.KTBLS, filPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	password := []byte("password")
+	ek, err := keystore.Encrypt(key, password, keystore.LightScryptParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a truncated nonce fails instead of panicking in crypto/cipher
+	truncated := *ek
+	truncated.Crypto.Nonce = ek.Crypto.Nonce[:4]
+	_, err = keystore.Decrypt(&truncated, password)
+	if err == nil {
+		t.Fatal("expect a truncated nonce to be rejected")
+	}
+
+	// scrypt parameters allocating more than SCRYPT_MAX_MEMORY are rejected before deriving
+	for _, params := range []keystore.ScryptParams{
+		{N: 1 << 30, R: 8, P: 1},
+		{N: 1 << 12, R: 1 << 20, P: 1},
+		{N: 1 << 12, R: 8, P: 1 << 20},
+		{N: 1000, R: 8, P: 1},
+	} {
+		crafted := *ek
+		crafted.Crypto.KDFParams = params
+		_, err = keystore.Decrypt(&crafted, password)
+		if err == nil {
+			t.Fatalf("expect scrypt parameters %+v to be rejected", params)
+		}
+	}
+
+	_, err = keystore.Encrypt(key, password, keystore.ScryptParams{N: 1 << 24, R: 8, P: 1})
+	if err == nil {
+		t.Fatal("expect Encrypt to reject parameters Decrypt refuses")
+	}
+}
+
+func TestKeystoreNetwork(t *testing.T) {
+	key, err := keystore.NewKey(gamevrf.KTBLS, filPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network := address.CurrentNetwork
+	defer func() { address.CurrentNetwork = network }()
+
+	// written by a mainnet process, read by a testnet one
+	password := []byte("password")
+	address.CurrentNetwork = address.Mainnet
+	ek, err := keystore.Encrypt(key, password, keystore.LightScryptParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(ek.Address, address.MainnetPrefix) {
+		t.Fatalf("unexpected keystore address %s", ek.Address)
+	}
+
+	address.CurrentNetwork = address.Testnet
+	decrypted, err := keystore.Decrypt(ek, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decrypted.PrivateKey, filPrivateKey) {
+		t.Fatal("decrypted key doesn't match")
+	}
+
+	// the address is still authenticated
+	other, err := keystore.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherAddr, err := other.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swapped := *ek
+	swapped.Address = otherAddr.String()
+	_, err = keystore.Decrypt(&swapped, password)
+	if err == nil {
+		t.Fatal("expect a swapped address to be rejected")
+	}
+}
@@ -0,0 +1,349 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+
+	"github.com/gorilla/websocket"
+)
+
+// mockLotus is a minimal stand-in for a lotus node serving ChainHead and ChainGetTipSetByHeight
+type mockLotus struct {
+	srv *httptest.Server
+
+	lck     sync.Mutex
+	head    int64
+	nulls   map[int64]bool
+	delay   time.Duration
+	calls   map[string]int
+	tickets func(height int64) [][]byte
+	token   string
+	fails   []int // statuses answered to the next requests
+	ids     []string
+	noWS    bool
+	stalled bool                // subscriptions stay open but aren't notified
+	subs    map[chan int64]bool // heads pushed to the ChainNotify subscriptions
+}
+
+// newMockLotus starts a mock lotus node whose chain head is at head
+func newMockLotus(t *testing.T, head int64) *mockLotus {
+	m := &mockLotus{
+		head:    head,
+		nulls:   make(map[int64]bool),
+		calls:   make(map[string]int),
+		tickets: mockTickets,
+		subs:    make(map[chan int64]bool),
+	}
+
+	m.srv = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	t.Cleanup(m.srv.Close)
+
+	return m
+}
+
+// mockTickets returns deterministic tickets for the blocks of a mock tipset
+func mockTickets(height int64) [][]byte {
+	return [][]byte{
+		[]byte(fmt.Sprintf("ticket-%d-a", height)),
+		[]byte(fmt.Sprintf("ticket-%d-b", height)),
+	}
+}
+
+// SetTickets replaces the function generating the tickets of the served tipsets
+func (m *mockLotus) SetTickets(tickets func(height int64) [][]byte) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.tickets = tickets
+}
+
+// SetToken makes the mock node require token as a bearer token
+func (m *mockLotus) SetToken(token string) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.token = token
+}
+
+// FailNext makes the mock node answer the next requests with statuses
+func (m *mockLotus) FailNext(statuses ...int) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.fails = append(m.fails, statuses...)
+}
+
+// IDs returns the ids of the served requests
+func (m *mockLotus) IDs() []string {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	return append([]string(nil), m.ids...)
+}
+
+// SetHead moves the chain head to head and notifies the subscriptions
+func (m *mockLotus) SetHead(head int64) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.head = head
+	if m.stalled {
+		return
+	}
+	for sub := range m.subs {
+		select {
+		case sub <- head:
+		default:
+		}
+	}
+}
+
+// SetWebsocket enables or disables the websocket endpoint, disabling it drops the subscriptions
+func (m *mockLotus) SetWebsocket(enabled bool) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.noWS = !enabled
+	if !enabled {
+		for sub := range m.subs {
+			close(sub)
+			delete(m.subs, sub)
+		}
+	}
+}
+
+// SetStalled keeps the subscriptions open without notifying them of the new heads
+func (m *mockLotus) SetStalled(stalled bool) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.stalled = stalled
+}
+
+// URL returns the rpc endpoint of the mock node
+func (m *mockLotus) URL() string {
+	return m.srv.URL
+}
+
+// SetNull marks a height as a null round
+func (m *mockLotus) SetNull(height int64) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.nulls[height] = true
+}
+
+// SetDelay makes the mock node wait before responding
+func (m *mockLotus) SetDelay(d time.Duration) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.delay = d
+}
+
+// Calls returns how many times method has been requested
+func (m *mockLotus) Calls(method string) int {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	return m.calls[method]
+}
+
+// TipSet builds the tipset served at height, walking back over null rounds as lotus does
+func (m *mockLotus) TipSet(height int64) *filrpc.TipSet {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	for m.nulls[height] {
+		height--
+	}
+
+	var blks []*filrpc.BlockHeader
+	for _, ticket := range m.tickets(height) {
+		blks = append(blks, &filrpc.BlockHeader{
+			Ticket: &filrpc.Ticket{VRFProof: ticket},
+			Height: uint64(height),
+		})
+	}
+
+	ts, err := filrpc.NewTipSet(blks)
+	if err != nil {
+		panic(err)
+	}
+
+	return ts
+}
+
+func (m *mockLotus) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		m.serveWebsocket(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+		ID     json.RawMessage   `json:"id"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.lck.Lock()
+	m.calls[req.Method]++
+	delay := m.delay
+	head := m.head
+	token := m.token
+	status := 0
+	if len(m.fails) > 0 {
+		status = m.fails[0]
+		m.fails = m.fails[1:]
+	}
+	m.lck.Unlock()
+
+	if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+		http.Error(w, "missing permission to invoke '"+req.Method+"'", http.StatusUnauthorized)
+		return
+	}
+
+	if status != 0 {
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	m.lck.Lock()
+	m.ids = append(m.ids, string(req.ID))
+	m.lck.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "Filecoin.ChainHead":
+		result = m.TipSet(head)
+	case "Filecoin.ChainGetTipSetByHeight":
+		var height int64
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &height) != nil {
+			http.Error(w, "bad params", http.StatusBadRequest)
+			return
+		}
+		result = m.TipSet(height)
+	default:
+		writeRPC(w, req.ID, nil, fmt.Sprintf("method %s not found", req.Method))
+		return
+	}
+
+	writeRPC(w, req.ID, result, "")
+}
+
+// serveWebsocket serves a ChainNotify subscription the way lotus does, the current head on channel 1
+// followed by an apply change for every SetHead
+func (m *mockLotus) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	m.lck.Lock()
+	noWS := m.noWS
+	m.lck.Unlock()
+
+	if noWS {
+		http.Error(w, "404 page not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var req struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if conn.ReadJSON(&req) != nil || req.Method != "Filecoin.ChainNotify" {
+		return
+	}
+
+	m.lck.Lock()
+	m.calls[req.Method]++
+	heads := make(chan int64, 16)
+	m.subs[heads] = true
+	head := m.head
+	m.lck.Unlock()
+
+	defer func() {
+		m.lck.Lock()
+		delete(m.subs, heads)
+		m.lck.Unlock()
+	}()
+
+	notify := func(typ string, height int64) error {
+		return conn.WriteJSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "xrpc.ch.val",
+			"params":  []interface{}{1, []filrpc.HeadChange{{Type: typ, Val: m.TipSet(height)}}},
+		})
+	}
+
+	if conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": 1}) != nil ||
+		notify(filrpc.HCCurrent, head) != nil {
+		return
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case height, ok := <-heads:
+			if !ok || notify(filrpc.HCApply, height) != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// writeRPC writes a json rpc response with either result or an error message
+func writeRPC(w http.ResponseWriter, id interface{}, result interface{}, errMsg string) {
+	rsp := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+	}
+	if errMsg != "" {
+		rsp["error"] = map[string]interface{}{"code": -32601, "message": errMsg}
+	} else {
+		rsp["result"] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rsp)
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestPolicyNullRounds(t *testing.T) {
+	m := newMockLotus(t, 2000)
+	// the default lookback requests height 1990
+	m.SetNull(1990)
+	m.SetNull(1989)
+
+	ctx := context.Background()
+	cases := []struct {
+		search gamevrf.NullRoundSearch
+		limit  uint64
+		base   uint64
+		fail   bool
+	}{
+		{search: gamevrf.NullRoundSearchBackward, limit: 10, base: 1988},
+		{search: gamevrf.NullRoundSearchBackward, limit: 1, fail: true},
+		{search: gamevrf.NullRoundSearchForward, limit: 10, base: 1991},
+		{search: gamevrf.NullRoundSearchNone, fail: true},
+	}
+
+	for _, c := range cases {
+		policy := gamevrf.DefaultPolicy()
+		policy.NullRoundSearch = c.search
+		policy.NullRoundLimit = c.limit
+
+		gg := gamevrf.NewWithProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), gamevrf.PolicyOption(policy))
+		vrfout, meta, err := gg.GenerateVRFWithMeta(ctx, gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, []byte("policy-entropy"))
+		if c.fail {
+			if err == nil {
+				t.Fatalf("%s search with limit %d should fail", c.search, c.limit)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if meta.RequestedHeight != 1990 || meta.BaseHeight != c.base || vrfout.Height != c.base {
+			t.Fatalf("%s search: unexpected meta %+v", c.search, meta)
+		}
+
+		if meta.NullRounds != 2 && c.search == gamevrf.NullRoundSearchBackward {
+			t.Fatalf("expect 2 null rounds, got %d", meta.NullRounds)
+		}
+	}
+}
+
+func TestPolicyFinality(t *testing.T) {
+	m := newMockLotus(t, 2000)
+
+	gg := gamevrf.NewWithProvider(gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())), gamevrf.PolicyOption(gamevrf.ECFinalityPolicy()))
+
+	ctx := context.Background()
+	entropy := []byte("finality-entropy")
+	vrfout, meta, err := gg.GenerateVRFWithMeta(ctx, gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.BaseHeight != 2000-gamevrf.FILECOIN_CHAIN_FINALITY || meta.Confirmations != gamevrf.FILECOIN_CHAIN_FINALITY {
+		t.Fatalf("unexpected meta %+v", meta)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gg.VerifyVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a VRF drawn from a recent base isn't final yet
+	recent, err := gamevrf.New(filrpc.NodeURLOption(m.URL())).GenerateVRF(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gg.VerifyVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, recent)
+	if err == nil {
+		t.Fatal("expect a non-final VRF base to be rejected")
+	}
+}
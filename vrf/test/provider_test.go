@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// fixtureTipSets builds tipsets for heights [from, to], skipping the null rounds
+func fixtureTipSets(t *testing.T, from, to uint64, nulls ...uint64) []*filrpc.TipSet {
+	isNull := make(map[uint64]bool)
+	for _, h := range nulls {
+		isNull[h] = true
+	}
+
+	var tipsets []*filrpc.TipSet
+	for h := from; h <= to; h++ {
+		if isNull[h] {
+			continue
+		}
+
+		ts, err := filrpc.NewTipSet([]*filrpc.BlockHeader{
+			{Ticket: &filrpc.Ticket{VRFProof: []byte(fmt.Sprintf("ticket-%d-a", h))}, Height: h},
+			{Ticket: &filrpc.Ticket{VRFProof: []byte(fmt.Sprintf("ticket-%d-b", h))}, Height: h},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tipsets = append(tipsets, ts)
+	}
+
+	return tipsets
+}
+
+func TestMemoryProviderNullRound(t *testing.T) {
+	p := gamevrf.NewMemoryProvider(fixtureTipSets(t, 100, 120, 110, 111)...)
+
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 111)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Height() != 109 {
+		t.Fatalf("null round should resolve to 109, got %d", ts.Height())
+	}
+
+	head, err := p.ChainHead(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Height() != 120 {
+		t.Fatalf("head height %d != 120", head.Height())
+	}
+
+	_, err = p.ChainGetTipSetByHeight(context.Background(), 121)
+	if err == nil {
+		t.Fatal("expect error for a future height")
+	}
+}
+
+func TestGameVRFOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tipsets.json")
+	err := gamevrf.WriteTipSetArchive(path, fixtureTipSets(t, 3303900, 3303964))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := gamevrf.NewFileProvider(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gg := gamevrf.NewWithProvider(p)
+
+	entropy := []byte("offline-entropy")
+	vrfout, err := gg.GenerateVRF(gamevrf.DomainSeparationTag_GameRound, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vrfout.Height != 3303964-gamevrf.GAME_CHAIN_EPOCH_LOOKBACK {
+		t.Fatalf("vrf height %d != %d", vrfout.Height, 3303964-gamevrf.GAME_CHAIN_EPOCH_LOOKBACK)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// verify against a fresh instance reading the same archive
+	p2, err := gamevrf.NewFileProvider(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gamevrf.NewWithProvider(p2).VerifyVRF(gamevrf.DomainSeparationTag_GameRound, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gg.VerifyVRF(gamevrf.DomainSeparationTag_GameRound, addr, []byte("other-entropy"), vrfout)
+	if err == nil {
+		t.Fatal("expect verify failure with different entropy")
+	}
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// quorumEndpoints starts n mock nodes, the ones listed in evil serve forged tickets
+func quorumEndpoints(t *testing.T, n int, evil ...int) ([]gamevrf.QuorumEndpoint, []*mockLotus) {
+	isEvil := make(map[int]bool)
+	for _, i := range evil {
+		isEvil[i] = true
+	}
+
+	var endpoints []gamevrf.QuorumEndpoint
+	var nodes []*mockLotus
+	for i := 0; i < n; i++ {
+		m := newMockLotus(t, 1000)
+		if isEvil[i] {
+			m.SetTickets(func(height int64) [][]byte {
+				return [][]byte{[]byte(fmt.Sprintf("forged-%d", height))}
+			})
+		}
+
+		nodes = append(nodes, m)
+		endpoints = append(endpoints, gamevrf.QuorumEndpoint{
+			Name:     fmt.Sprintf("node-%d", i),
+			Provider: gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())),
+		})
+	}
+
+	return endpoints, nodes
+}
+
+func TestQuorumProvider(t *testing.T) {
+	endpoints, nodes := quorumEndpoints(t, 3, 2)
+
+	var lck sync.Mutex
+	var reports []*gamevrf.QuorumReport
+	p, err := gamevrf.NewQuorumProvider(2, endpoints, gamevrf.QuorumReporterOption(func(report *gamevrf.QuorumReport) {
+		lck.Lock()
+		defer lck.Unlock()
+		reports = append(reports, report)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 900)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	honest := nodes[0].TipSet(900)
+	if !ts.MinTicket().Equals(honest.MinTicket()) {
+		t.Fatal("quorum returned the forged tipset")
+	}
+
+	if len(reports) != 1 || len(reports[0].Disagreed) != 1 || reports[0].Disagreed[0] != "node-2" {
+		t.Fatalf("node-2 should be reported as disagreeing, got %+v", reports)
+	}
+
+	if p.Disagreements()["node-2"] != 1 {
+		t.Fatalf("node-2 disagreement count %d != 1", p.Disagreements()["node-2"])
+	}
+
+	// all nodes must agree
+	strict, err := gamevrf.NewQuorumProvider(3, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = strict.ChainGetTipSetByHeight(context.Background(), 900)
+	if err == nil {
+		t.Fatal("expect no quorum with a forging node")
+	}
+}
+
+func TestQuorumTipSetKey(t *testing.T) {
+	honest := fixtureTipSetWithCids(t, 900)
+
+	// same height and min ticket, but a block is missing
+	blks := honest.Blocks()[:2]
+	divergent, err := filrpc.NewTipSetWithCids(blks, honest.Cids()[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !divergent.MinTicket().Equals(honest.MinTicket()) {
+		t.Fatal("divergent tipset should keep the min ticket")
+	}
+
+	endpoints := []gamevrf.QuorumEndpoint{
+		{Name: "node-0", Provider: gamevrf.NewMemoryProvider(honest)},
+		{Name: "node-1", Provider: gamevrf.NewMemoryProvider(honest)},
+		{Name: "node-2", Provider: gamevrf.NewMemoryProvider(divergent)},
+	}
+
+	var reports []*gamevrf.QuorumReport
+	p, err := gamevrf.NewQuorumProvider(2, endpoints, gamevrf.QuorumReporterOption(func(report *gamevrf.QuorumReport) {
+		reports = append(reports, report)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 900)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ts.Blocks()) != len(honest.Blocks()) {
+		t.Fatalf("quorum returned %d blocks, expect %d", len(ts.Blocks()), len(honest.Blocks()))
+	}
+
+	if len(reports) != 1 || len(reports[0].Disagreed) != 1 || reports[0].Disagreed[0] != "node-2" {
+		t.Fatalf("node-2 should be reported as disagreeing, got %+v", reports)
+	}
+
+	// two single answers are conflicting quorums of 1 whatever the order they are grouped in
+	single, err := gamevrf.NewQuorumProvider(1, []gamevrf.QuorumEndpoint{endpoints[0], endpoints[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err = single.ChainGetTipSetByHeight(context.Background(), 900)
+		if err == nil {
+			t.Fatal("expect conflicting quorums")
+		}
+	}
+}
+
+func TestQuorumGameVRF(t *testing.T) {
+	endpoints, nodes := quorumEndpoints(t, 3, 0)
+	nodes[1].srv.Close()
+
+	p, err := gamevrf.NewQuorumProvider(1, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one honest, one forged and one unreachable node can't reach a unique quorum of 1
+	_, err = p.ChainGetTipSetByHeight(context.Background(), 900)
+	if err == nil {
+		t.Fatal("expect conflicting quorums")
+	}
+
+	p, err = gamevrf.NewQuorumProvider(2, append(endpoints, quorumHonest(t)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gg := gamevrf.NewWithProvider(p)
+
+	entropy := []byte("quorum-entropy")
+	vrfout, err := gg.GenerateVRF(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gg.VerifyVRF(gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := p.Disagreements()
+	if counts["node-0"] == 0 || counts["node-1"] == 0 {
+		t.Fatalf("forging and unreachable nodes should be counted, got %v", counts)
+	}
+}
+
+// quorumHonest returns an extra honest endpoint
+func quorumHonest(t *testing.T) []gamevrf.QuorumEndpoint {
+	m := newMockLotus(t, 1000)
+	return []gamevrf.QuorumEndpoint{{
+		Name:     "honest",
+		Provider: gamevrf.NewLotusProvider(filrpc.NodeURLOption(m.URL())),
+	}}
+}
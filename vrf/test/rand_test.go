@@ -2,11 +2,13 @@ package test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 
-	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
 	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
 	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
 
@@ -20,17 +22,31 @@ var (
 	// filecoin bls public key
 	filPublicKey = []byte{146, 209, 52, 147, 166, 127, 130, 148, 172, 13, 162, 254, 17, 85, 254, 151, 93, 182, 28, 218, 103, 106, 200, 115, 178, 101, 156, 74, 25, 214, 220, 136, 167, 32, 147, 231, 40, 250, 149, 109, 229, 58, 7, 135, 214, 93, 55, 169}
 
+	// filProof is the VRF of filPrivateKey over the mainnet tipset at chainHeight
 	filProof = []byte{166, 151, 5, 61, 189, 201, 203, 69, 188, 20, 9, 50, 223, 153, 238, 59, 149, 71, 92, 205, 245, 57, 9, 168, 156, 163, 49, 215, 203, 159, 209, 245, 110, 78, 130, 62, 224, 136, 188, 64, 79, 245, 145, 21, 119, 13, 43, 8, 3, 231, 35, 65, 212, 42, 11, 44, 247, 146, 120, 206, 82, 252, 203, 131, 1, 13, 150, 229, 244, 12, 165, 170, 77, 27, 239, 148, 184, 106, 124, 46, 182, 222, 112, 241, 205, 168, 133, 58, 106, 104, 70, 68, 250, 70, 84, 27}
 )
 
-func TestVRFGenVerify(t *testing.T) {
-	nodeURL := "http://api.node.glif.io/rpc/v1"
+// mainnetFixture is a tipset archive of the mainnet segment ending at chainHeight and the worker keys of its miners,
+// recorded with go run ./vrf/test/record -height 3303964 -out vrf/test/testdata/mainnet_fixture.json
+const mainnetFixture = "testdata/mainnet_fixture.json"
 
-	client := filrpc.New(
-		filrpc.NodeURLOption(nodeURL),
-	)
+// loadMainnetFixture serves the recorded mainnet segment, tests needing it are skipped until it is recorded
+// since it can only be recorded from a mainnet node
+func loadMainnetFixture(t *testing.T) *gamevrf.FileProvider {
+	if _, err := os.Stat(mainnetFixture); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("%s isn't recorded, record it with: go run ./vrf/test/record -height %d -out vrf/test/%s", mainnetFixture, chainHeight, mainnetFixture)
+	}
 
-	tps, err := client.ChainGetTipSetByHeight(chainHeight)
+	p, err := gamevrf.NewFileProvider(mainnetFixture)
+	if err != nil {
+		t.Fatalf("%s, record it with: go run ./vrf/test/record -height %d -out vrf/test/%s", err, chainHeight, mainnetFixture)
+	}
+
+	return p
+}
+
+func TestVRFGenVerify(t *testing.T) {
+	tps, err := loadMainnetFixture(t).ChainGetTipSetByHeight(context.Background(), chainHeight)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -76,13 +92,7 @@ func TestVRFGenVerify(t *testing.T) {
 }
 
 func TestVRFGenVerify2(t *testing.T) {
-	nodeURL := "http://api.node.glif.io/rpc/v1"
-
-	client := filrpc.New(
-		filrpc.NodeURLOption(nodeURL),
-	)
-
-	tps, err := client.ChainGetTipSetByHeight(chainHeight)
+	tps, err := loadMainnetFixture(t).ChainGetTipSetByHeight(context.Background(), chainHeight)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -121,9 +131,7 @@ func TestVRFGenVerify2(t *testing.T) {
 }
 
 func TestVRFGenVerify3(t *testing.T) {
-	nodeURL := "http://api.node.glif.io/rpc/v1"
-
-	gg := gamevrf.New(filrpc.NodeURLOption(nodeURL))
+	gg := gamevrf.NewWithProvider(loadMainnetFixture(t))
 
 	privateKey := filPrivateKey
 	publicKey := filPublicKey
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// fixtureTipSetWithCids builds a tipset at height whose blocks have cids
+func fixtureTipSetWithCids(t *testing.T, height uint64) *filrpc.TipSet {
+	prefix := cid.NewPrefixV1(cid.DagCBOR, multihash.BLAKE2B_MIN+31)
+
+	var blks []*filrpc.BlockHeader
+	var cids []cid.Cid
+	for _, suffix := range []string{"a", "b", "c"} {
+		ticket := []byte(fmt.Sprintf("ticket-%d-%s", height, suffix))
+		c, err := prefix.Sum(ticket)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blks = append(blks, &filrpc.BlockHeader{Ticket: &filrpc.Ticket{VRFProof: ticket}, Height: height})
+		cids = append(cids, c)
+	}
+
+	ts, err := filrpc.NewTipSetWithCids(blks, cids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ts
+}
+
+func TestVRFReceipt(t *testing.T) {
+	var tipsets []*filrpc.TipSet
+	for h := uint64(500); h <= 520; h++ {
+		tipsets = append(tipsets, fixtureTipSetWithCids(t, h))
+	}
+	provider := gamevrf.NewMemoryProvider(tipsets...)
+
+	gg := gamevrf.NewWithProvider(provider)
+
+	entropy := []byte("receipt-entropy")
+	vrfout, err := gg.GenerateVRF(gamevrf.DomainSeparationTag_GameLottery, filPrivateKey, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := gg.Receipt(gamevrf.DomainSeparationTag_GameLottery, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(receipt.TipSetCids) != 3 {
+		t.Fatalf("receipt should carry 3 tipset cids, got %d", len(receipt.TipSetCids))
+	}
+
+	// cbor round trip
+	buf := new(bytes.Buffer)
+	err = receipt.MarshalCBOR(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromCBOR, err := gamevrf.UnmarshalReceiptCBOR(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gamevrf.VerifyReceipt(fromCBOR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// receipts of version 1 predate the base kind and are min ticket receipts
+	fromCBOR.Version = 1
+	err = gamevrf.VerifyReceipt(fromCBOR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromCBOR.BaseKind = gamevrf.BaseKind_Drand
+	err = gamevrf.VerifyReceipt(fromCBOR, nil)
+	if err == nil {
+		t.Fatal("expect a version 1 receipt with a drand base to be rejected")
+	}
+
+	fromCBOR.Version = gamevrf.VRF_RECEIPT_VERSION + 1
+	err = gamevrf.VerifyReceipt(fromCBOR, nil)
+	if err == nil {
+		t.Fatal("expect an unknown receipt version to be rejected")
+	}
+
+	// json round trip with only the entropy hash
+	receipt.HashEntropy()
+	b, err := gamevrf.MarshalReceiptJSON(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromJSON, err := gamevrf.UnmarshalReceiptJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gamevrf.VerifyReceipt(fromJSON, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gamevrf.VerifyReceipt(fromJSON, []byte("other-entropy"))
+	if err == nil {
+		t.Fatal("expect verify failure with entropy not matching the hash")
+	}
+
+	err = gamevrf.VerifyReceiptOnline(context.Background(), provider, fromJSON, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a receipt whose cids don't match the chain
+	fromJSON.TipSetCids[0], fromJSON.TipSetCids[1] = fromJSON.TipSetCids[1], fromJSON.TipSetCids[0]
+	err = gamevrf.VerifyReceiptOnline(context.Background(), provider, fromJSON, entropy)
+	if err == nil {
+		t.Fatal("expect online verify failure with wrong tipset cids")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+)
+
+// record writes the tipsets of a chain segment and the worker keys of their miners to a tipset archive,
+// the archive serves as an offline fixture for gamevrf.NewFileProvider. The segment must include a null round
+// and a block without beacon entries unless -allow-plain is set, increase -depth until it does.
+func main() {
+	nodeURL := flag.String("node", "https://api.node.glif.io/rpc/v1", "lotus rpc endpoint")
+	workerURL := flag.String("worker-node", "", "trusted lotus rpc endpoint the worker keys are read from, -node if empty")
+	allowPlain := flag.Bool("allow-plain", false, "record a segment without null round or block without beacon entries")
+	height := flag.Int64("height", 0, "highest height to record")
+	depth := flag.Int64("depth", gamevrf.TICKET_BEACON_SEARCH_LIMIT, "number of epochs recorded below height")
+	out := flag.String("out", "tipsets.json", "archive file to write")
+	flag.Parse()
+
+	if *height <= *depth {
+		fmt.Println("height must be greater than depth")
+		os.Exit(1)
+	}
+
+	if *workerURL == "" {
+		*workerURL = *nodeURL
+	}
+
+	provider := gamevrf.NewLotusProvider(filrpc.NodeURLOption(*nodeURL), filrpc.TimeoutOption(30*time.Second))
+	resolver := gamevrf.NewLotusWorkerResolver(filrpc.NodeURLOption(*workerURL), filrpc.TimeoutOption(30*time.Second))
+
+	ctx := context.Background()
+	mem := gamevrf.NewMemoryProvider()
+	workers := make(gamevrf.WorkerKeys)
+	var nulls, beaconless []uint64
+	for h := *height - *depth; h <= *height; h++ {
+		ts, err := provider.ChainGetTipSetByHeight(ctx, h)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if ts.Height() != uint64(h) {
+			// a null round, lotus returns the tipset below
+			nulls = append(nulls, uint64(h))
+			continue
+		}
+		mem.Add(ts)
+
+		for _, blk := range ts.Blocks() {
+			if len(blk.BeaconEntries) == 0 {
+				beaconless = append(beaconless, ts.Height())
+				break
+			}
+		}
+
+		miner := ts.MinTicketBlock().Miner
+		if _, ok := workers[miner]; ok {
+			continue
+		}
+
+		worker, err := resolver.WorkerKey(ctx, miner, ts.Height())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		workers[miner] = worker
+	}
+
+	fmt.Printf("null rounds %v, tipsets with blocks without beacon entries %v\n", nulls, beaconless)
+	if !*allowPlain && (len(nulls) == 0 || len(beaconless) == 0) {
+		fmt.Println("the segment needs a null round and a block without beacon entries, increase -depth")
+		os.Exit(1)
+	}
+
+	var minerWorkers []gamevrf.MinerWorker
+	for miner, worker := range workers {
+		minerWorkers = append(minerWorkers, gamevrf.MinerWorker{Miner: miner, Worker: worker})
+	}
+
+	err := gamevrf.WriteArchive(*out, &gamevrf.TipSetArchive{
+		Version: gamevrf.TIPSET_ARCHIVE_VERSION,
+		TipSets: mem.TipSets(),
+		Workers: minerWorkers,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
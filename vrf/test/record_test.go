@@ -0,0 +1,146 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+)
+
+// playRound is game code drawing through any Rng
+func playRound(r trand.RngV1) []int {
+	out := trand.Perm(r, 5)
+	out = append(out, r.IntRange(1, 6), r.Intn(100), int(r.Float64()*1000))
+	return out
+}
+
+func TestRecorderReplay(t *testing.T) {
+	rec := trand.NewRecorder(trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_Latest))
+	played := playRound(rec)
+
+	transcript := rec.Transcript()
+	if transcript.Hash != rec.Hash() || len(transcript.Draws) != 7 {
+		t.Fatalf("unexpected transcript of %d draws", len(transcript.Draws))
+	}
+
+	b, err := transcript.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded trand.Transcript
+	err = decoded.UnmarshalBinary(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the committed hash must match the decoded draws
+	if decoded.Hash != rec.Hash() {
+		t.Fatal("decoded transcript hash doesn't match")
+	}
+
+	err = decoded.Replay(trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_Latest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// running the game code again over a verifier checks every draw
+	v := trand.NewVerifier(trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_Latest), &decoded)
+	replayed := playRound(v)
+	if err := v.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range played {
+		if played[i] != replayed[i] {
+			t.Fatalf("replayed round %v != %v", replayed, played)
+		}
+	}
+}
+
+func TestRecorderDivergence(t *testing.T) {
+	rec := trand.NewRecorder(trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest))
+	playRound(rec)
+	transcript := rec.Transcript()
+
+	// a server claiming another dice roll
+	tampered := rec.Transcript()
+	tampered.Draws[4].Result = transcript.Draws[4].Result%6 + 1
+	tampered.Hash = rec.Hash()
+	if err := tampered.Replay(trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest)); err == nil {
+		t.Fatal("expect a transcript not matching its hash to fail")
+	}
+
+	b, err := tampered.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tampered.UnmarshalBinary(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tampered.Replay(trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest))
+	var divergence *trand.DivergenceError
+	if !errors.As(err, &divergence) || divergence.Expect.Seq != 4 {
+		t.Fatalf("expect a divergence at draw 4, got %v", err)
+	}
+
+	// another seed diverges
+	seed := trandSeed()
+	seed[0]++
+	v := trand.NewVerifier(trand.NewRng(seed, trand.RNGType_Normal, trand.AlgorithmVersion_Latest), transcript)
+	playRound(v)
+	if !errors.As(v.Finish(), &divergence) {
+		t.Fatalf("expect a divergence, got %v", v.Err())
+	}
+
+	// game code drawing less than the transcript
+	v = trand.NewVerifier(trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest), transcript)
+	trand.Perm(v, 5)
+	if v.Err() != nil || v.Finish() == nil {
+		t.Fatal("expect missing draws to fail")
+	}
+
+	if err := tampered.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Fatal("expect a truncated transcript to fail")
+	}
+}
+
+func TestRecorderPlainRng(t *testing.T) {
+	// an Rng implemented outside the package is recorded and replayed like the generators of NewRng
+	newRng := func() trand.Rng {
+		return plainRng{trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_V1)}
+	}
+
+	rec := trand.NewRecorder(newRng())
+	played := playRound(rec)
+
+	// the range draws follow AlgorithmVersion_V1 over Uint64
+	expect := playRound(trand.NewRng(trandSeed(), trand.RNGType_Cipher, trand.AlgorithmVersion_V1))
+	for i := range played {
+		if played[i] != expect[i] {
+			t.Fatalf("recorded round %v != %v", played, expect)
+		}
+	}
+
+	if rec.Version() != trand.AlgorithmVersion_V1 {
+		t.Fatalf("unexpected version %d", rec.Version())
+	}
+
+	_, err := rec.MarshalBinary()
+	if err == nil {
+		t.Fatal("expect a plain Rng not to be snapshotted")
+	}
+
+	err = rec.Transcript().Replay(newRng())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := trand.NewVerifier(newRng(), rec.Transcript())
+	playRound(v)
+	if err := v.Finish(); err != nil {
+		t.Fatal(err)
+	}
+}
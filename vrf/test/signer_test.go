@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf/remotesigner"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// newMockSigner starts a signing daemon holding filPrivateKey
+func newMockSigner(t *testing.T, token string) *httptest.Server {
+	local, err := gamevrf.NewLocalSigner(filPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(remotesigner.NewHandler(local, token))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestLocalSignerMatchesPrivateKey(t *testing.T) {
+	signer, err := gamevrf.NewLocalSigner(filPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	pub, err := signer.PublicKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(pub, filPublicKey) {
+		t.Fatal("public key doesn't match")
+	}
+
+	ts := fixtureTipSets(t, 200, 200)[0]
+	entropy := []byte("signer-entropy")
+	want, err := gamevrf.FilGenerateVRFByTipSet(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, ts, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gamevrf.FilGenerateVRFByTipSetWithSigner(ctx, gamevrf.DomainSeparationTag_GameBasic, signer, ts, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Height != want.Height || !bytes.Equal(got.Proof, want.Proof) {
+		t.Fatal("signer VRF doesn't match the private key VRF")
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	srv := newMockSigner(t, "secret")
+	m := newMockLotus(t, 2000)
+
+	gg := gamevrf.New(filrpc.NodeURLOption(m.URL()))
+	signer := remotesigner.New(srv.URL, remotesigner.TokenOption("secret"))
+
+	ctx := context.Background()
+	entropy := []byte("remote-entropy")
+	vrfout, _, err := gg.GenerateVRFWithSigner(ctx, gamevrf.DomainSeparationTag_GameBasic, signer, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := gamevrf.SignerAddress(ctx, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr != expect {
+		t.Fatalf("remote signer address %s != %s", addr, expect)
+	}
+
+	err = gg.VerifyVRFContext(ctx, gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = remotesigner.New(srv.URL, remotesigner.TokenOption("wrong")).PublicKey(ctx)
+	if err == nil {
+		t.Fatal("expect a wrong token to be rejected")
+	}
+}
+
+// forgingSigner answers the right public key but signatures of another message
+type forgingSigner struct {
+	*gamevrf.LocalSigner
+}
+
+func (s forgingSigner) Sign(ctx context.Context, msg []byte) ([]byte, error) {
+	return s.LocalSigner.Sign(ctx, []byte("something else"))
+}
+
+func TestRemoteSignerForgedSignature(t *testing.T) {
+	local, err := gamevrf.NewLocalSigner(filPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(remotesigner.NewHandler(forgingSigner{local}, ""))
+	t.Cleanup(srv.Close)
+
+	_, err = remotesigner.New(srv.URL).SignVRF(context.Background(), gamevrf.DomainSeparationTag_GameBasic, []byte("rbase"), 100, nil)
+	if err == nil {
+		t.Fatal("expect a forged signature to be rejected")
+	}
+}
+
+func TestRemoteSignerRejectsMessages(t *testing.T) {
+	srv := newMockSigner(t, "")
+
+	ctx := context.Background()
+	_, err := remotesigner.New(srv.URL).Sign(ctx, make([]byte, 32))
+	if err == nil {
+		t.Fatal("expect the remote signer to refuse signing a message")
+	}
+
+	// a client of the raw message protocol must not get a signature
+	for _, body := range []string{`{"message":"AAAA"}`, `{"pers":1}`, `not json`} {
+		rsp, err := http.Post(srv.URL+remotesigner.SignPath, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsp.Body.Close()
+
+		if rsp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("sign request %s answered status %d", body, rsp.StatusCode)
+		}
+	}
+}
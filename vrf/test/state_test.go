@@ -0,0 +1,127 @@
+package test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+
+	cc "github.com/nixberg/chacha-rng-go"
+)
+
+func TestStateChachaMatchesNixberg(t *testing.T) {
+	// the cipher generator was built on github.com/nixberg/chacha-rng-go, replays recorded with it must reproduce
+	seed := trandSeed()
+	var seed32 [8]uint32
+	for i := range seed32 {
+		seed32[i] = binary.LittleEndian.Uint32(seed[i*4:])
+	}
+
+	ref := cc.Seeded8(seed32, 0)
+	r := trand.NewRng(seed, trand.RNGType_Cipher)
+	for i := 0; i < 10000; i++ {
+		if a, b := r.Uint64(), ref.Uint64(); a != b {
+			t.Fatalf("draw %d: %x != %x", i, a, b)
+		}
+	}
+}
+
+func TestStateSnapshot(t *testing.T) {
+	for _, typ := range []trand.RNGType{trand.RNGType_Normal, trand.RNGType_Cipher} {
+		r := trand.NewRng(trandSeed(), typ, trand.AlgorithmVersion_V1)
+		// stop in the middle of a chacha block
+		for i := 0; i < 13; i++ {
+			r.Uint64()
+		}
+
+		snapshot, err := r.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restored, err := trand.UnmarshalRng(snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if restored.Version() != trand.AlgorithmVersion_V1 {
+			t.Fatalf("%s: restored version %d", typ, restored.Version())
+		}
+
+		for i := 0; i < 100; i++ {
+			if a, b := r.Intn(1000), restored.Intn(1000); a != b {
+				t.Fatalf("%s: draw %d after restore %d != %d", typ, i, b, a)
+			}
+		}
+	}
+
+	snapshot, err := trand.NewRng(trandSeed(), trand.RNGType_Normal).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := trand.NewRng(trandSeed(), trand.RNGType_Cipher).UnmarshalBinary(snapshot); err == nil {
+		t.Fatal("expect a snapshot of another generator type to fail")
+	}
+
+	if _, err := trand.UnmarshalRng(snapshot[:len(snapshot)-1]); err == nil {
+		t.Fatal("expect a truncated snapshot to fail")
+	}
+}
+
+func TestStateSeek(t *testing.T) {
+	r := trand.NewRng(trandSeed(), trand.RNGType_Cipher)
+	var draws []uint64
+	for i := 0; i < 100; i++ {
+		draws = append(draws, r.Uint64())
+	}
+
+	seeker, ok := trand.NewRng(trandSeed(), trand.RNGType_Cipher).(trand.Seeker)
+	if !ok {
+		t.Fatal("cipher generators must be seekable")
+	}
+
+	for _, n := range []uint64{0, 1, 7, 8, 9, 63, 64, 99} {
+		seeker.SeekTo(n)
+		if v := seeker.(trand.Rng).Uint64(); v != draws[n] {
+			t.Fatalf("draw %d after seek %x != %x", n, v, draws[n])
+		}
+	}
+
+	if _, ok := trand.NewRng(trandSeed(), trand.RNGType_Normal).(trand.Seeker); ok {
+		t.Fatal("normal generators can't seek")
+	}
+}
+
+func TestStateJump(t *testing.T) {
+	// jumps are linear maps of the state, they commute with drawing
+	for _, jump := range []func(trand.Jumper){trand.Jumper.Jump, trand.Jumper.LongJump} {
+		a := trand.NewRng(trandSeed(), trand.RNGType_Normal)
+		b := trand.NewRng(trandSeed(), trand.RNGType_Normal)
+
+		jump(a.(trand.Jumper))
+		for i := 0; i < 5; i++ {
+			b.Uint64()
+		}
+		jump(b.(trand.Jumper))
+
+		unjumped := trand.NewRng(trandSeed(), trand.RNGType_Normal)
+		for i := 0; i < 5; i++ {
+			a.Uint64()
+			unjumped.Uint64()
+		}
+
+		v := a.Uint64()
+		if v != b.Uint64() {
+			t.Fatal("jump doesn't commute with drawing")
+		}
+
+		if v == unjumped.Uint64() {
+			t.Fatal("jump doesn't move the generator")
+		}
+	}
+
+	if _, ok := trand.NewRng(trandSeed(), trand.RNGType_Cipher).(trand.Jumper); ok {
+		t.Fatal("cipher generators can't jump")
+	}
+}
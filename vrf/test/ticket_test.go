@@ -0,0 +1,208 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/filrpc"
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/gamevrf"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// ticketFixture is a synthetic chain segment (3303960, null round 3303961, 3303962 without beacon entries, 3303963)
+// of made up t0 miners whose tickets are signed by generated worker keys. It exercises the error paths,
+// TestVerifyMinTicketMainnet checks the verifier against the recorded mainnet segment.
+const ticketFixture = "testdata/ticket_synthetic_fixture.json"
+
+func TestVerifyMinTicket(t *testing.T) {
+	p, err := gamevrf.NewFileProvider(ticketFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := gamevrf.NewTicketVerifier(p, p, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT)
+
+	for _, h := range []int64{3303962, 3303963} {
+		ts, err := p.ChainGetTipSetByHeight(context.Background(), h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = v.VerifyMinTicket(context.Background(), ts)
+		if err != nil {
+			t.Fatalf("height %d: %s", h, err)
+		}
+	}
+}
+
+// TestVerifyMinTicketMainnet verifies the min tickets of the recorded mainnet segment with the recorded worker keys,
+// the segment has a null round and a block without beacon entries. It is skipped until the segment is recorded.
+func TestVerifyMinTicketMainnet(t *testing.T) {
+	p := loadMainnetFixture(t)
+	tipsets := p.TipSets()
+
+	var nulls, beaconless int
+	for i, ts := range tipsets {
+		if i > 0 && ts.Height() != tipsets[i-1].Height()+1 {
+			nulls++
+		}
+		for _, blk := range ts.Blocks() {
+			if len(blk.BeaconEntries) == 0 {
+				beaconless++
+			}
+		}
+	}
+
+	if tipsets[len(tipsets)-1].Height() != uint64(chainHeight) {
+		t.Fatalf("the recorded segment ends at %d, expect the mainnet height %d", tipsets[len(tipsets)-1].Height(), chainHeight)
+	}
+
+	if nulls == 0 || beaconless == 0 {
+		t.Fatalf("the recorded segment has %d null rounds and %d blocks without beacon entries, record a segment with both", nulls, beaconless)
+	}
+
+	// the archive must carry the worker key of every min ticket miner, not rely on a node to resolve it
+	for _, ts := range tipsets[1:] {
+		blk := ts.MinTicketBlock()
+		if _, err := p.WorkerKey(context.Background(), blk.Miner, blk.Height); err != nil {
+			t.Fatalf("height %d: the recorded segment has no worker key of %s", ts.Height(), blk.Miner)
+		}
+	}
+
+	v := gamevrf.NewTicketVerifier(p, p, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT)
+	for _, ts := range tipsets[1:] {
+		err := v.VerifyMinTicket(context.Background(), ts)
+		if err != nil {
+			t.Fatalf("height %d: %s", ts.Height(), err)
+		}
+	}
+}
+
+func TestVerifyMinTicketForged(t *testing.T) {
+	p, err := gamevrf.NewFileProvider(ticketFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 3303963)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a node handing out a fake rbase for the min ticket block
+	var blks []*filrpc.BlockHeader
+	for _, blk := range ts.Blocks() {
+		forged := *blk
+		forged.Ticket = &filrpc.Ticket{VRFProof: append([]byte{}, blk.Ticket.VRFProof...)}
+		forged.Ticket.VRFProof[10] ^= 0xff
+		blks = append(blks, &forged)
+	}
+	forgedTs, err := filrpc.NewTipSetWithCids(blks, ts.Cids())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := gamevrf.NewTicketVerifier(p, p, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT)
+	err = v.VerifyMinTicket(context.Background(), forgedTs)
+	if err == nil {
+		t.Fatal("expect forged ticket to be rejected")
+	}
+
+	// the wrong worker key
+	other, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workers := gamevrf.WorkerKeys{ts.MinTicketBlock().Miner: other}
+	err = gamevrf.NewTicketVerifier(p, workers, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT).VerifyMinTicket(context.Background(), ts)
+	if err == nil {
+		t.Fatal("expect ticket check with the wrong worker key to fail")
+	}
+
+	// a node dropping the parents of the block to skip the parent check
+	var orphans []*filrpc.BlockHeader
+	for _, blk := range ts.Blocks() {
+		orphan := *blk
+		orphan.Parents = nil
+		orphans = append(orphans, &orphan)
+	}
+	orphanTs, err := filrpc.NewTipSetWithCids(orphans, ts.Cids())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gamevrf.NewTicketVerifier(p, p, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT).VerifyMinTicket(context.Background(), orphanTs)
+	if err == nil {
+		t.Fatal("expect a block without parents to be rejected")
+	}
+
+	// or the cids of the parent tipset
+	parent, err := p.ChainGetTipSetByHeight(context.Background(), 3303962)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noCids, err := filrpc.NewTipSet(parent.Blocks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped := gamevrf.NewMemoryProvider(noCids)
+	err = gamevrf.NewTicketVerifier(dropped, p, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT).VerifyMinTicket(context.Background(), ts)
+	if err == nil {
+		t.Fatal("expect a parent tipset without cids to be rejected")
+	}
+
+	// like mainnet, networks upgraded at genesis include the parent ticket, a pre smoke input doesn't
+	err = gamevrf.NewTicketVerifier(p, p, 0).VerifyMinTicket(context.Background(), ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gamevrf.NewTicketVerifier(p, p, 3303963).VerifyMinTicket(context.Background(), ts)
+	if err == nil {
+		t.Fatal("expect ticket check with the pre smoke input to fail")
+	}
+}
+
+func TestGameVRFTicketVerify(t *testing.T) {
+	p, err := gamevrf.NewFileProvider(ticketFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := p.ChainGetTipSetByHeight(context.Background(), 3303963)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entropy := []byte("ticket-entropy")
+	vrfout, err := gamevrf.FilGenerateVRFByTipSet(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, ts, entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := address.NewBLSAddress(filPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gg := gamevrf.NewWithProvider(
+		p,
+		gamevrf.TicketVerifyOption(p, gamevrf.FILECOIN_MAINNET_SMOKE_HEIGHT),
+	)
+
+	err = gg.VerifyVRF(gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first recorded tipset has no parent to check its ticket against
+	vrfout, err = gamevrf.FilGenerateVRFByTipSet(gamevrf.DomainSeparationTag_GameBasic, filPrivateKey, p.TipSets()[0], entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gg.VerifyVRF(gamevrf.DomainSeparationTag_GameBasic, addr, entropy, vrfout)
+	if err == nil {
+		t.Fatal("expect ticket check without the parent tipset to fail")
+	}
+}
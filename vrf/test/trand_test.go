@@ -0,0 +1,129 @@
+package test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan-game-sdk/vrf/trand"
+)
+
+func trandSeed() [32]byte {
+	var seed [32]byte
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	return seed
+}
+
+func TestTrandLegacyIntn(t *testing.T) {
+	// recorded before the algorithm versions were introduced, replays depend on them
+	expect := map[trand.RNGType][]int{
+		trand.RNGType_Normal: {794, 206, 230, 265, 947, 985, 355, 266, 543, 394},
+		trand.RNGType_Cipher: {416, 199, 311, 795, 233, 990, 577, 837, 350, 599},
+	}
+
+	for typ, values := range expect {
+		for _, rng := range []trand.Rng{trand.NewRng(trandSeed(), typ), trand.NewRng(trandSeed(), typ, trand.AlgorithmVersion_Legacy)} {
+			var got []int
+			for i := 0; i < len(values); i++ {
+				got = append(got, rng.Intn(1000))
+			}
+
+			if !reflect.DeepEqual(got, values) {
+				t.Fatalf("%s: legacy Intn %v != %v", typ, got, values)
+			}
+		}
+	}
+}
+
+func TestTrandV1Intn(t *testing.T) {
+	// for small n the multiply and reject method mostly agrees with the legacy mapping, for large n
+	// the legacy mapping only reaches multiples of the float64 precision
+	const n = 1<<62 + 3
+	expect := map[trand.RNGType][]int{
+		trand.RNGType_Normal: {3663816989034353807, 951060507606478567, 1225527536559126262, 4371629738713353026, 4545572716333511690},
+		trand.RNGType_Cipher: {1921564363776099665, 920055165457381942, 1438456582785852414, 3670344910571805390, 1077383233868097472},
+	}
+
+	for typ, values := range expect {
+		rng := trand.NewRng(trandSeed(), typ, trand.AlgorithmVersion_V1)
+		legacy := trand.NewRng(trandSeed(), typ)
+		var got []int
+		for i := 0; i < len(values); i++ {
+			v := rng.Intn(n)
+			if v == legacy.Intn(n) {
+				t.Fatalf("%s: v1 Intn %d matches the legacy mapping", typ, v)
+			}
+			got = append(got, v)
+		}
+
+		if !reflect.DeepEqual(got, values) {
+			t.Fatalf("%s: v1 Intn %#v != %v", typ, got, values)
+		}
+	}
+}
+
+func TestTrandUniform(t *testing.T) {
+	for _, typ := range []trand.RNGType{trand.RNGType_Normal, trand.RNGType_Cipher} {
+		rng := trand.NewRng(trandSeed(), typ, trand.AlgorithmVersion_Latest)
+
+		const n, draws = 6, 60000
+		var counts [n]int
+		for i := 0; i < draws; i++ {
+			counts[rng.Uint64n(n)]++
+		}
+
+		// chi-square with 5 degrees of freedom, 20.5 is the 0.999 quantile
+		var chi2 float64
+		for _, c := range counts {
+			d := float64(c) - draws/n
+			chi2 += d * d / (draws / n)
+		}
+		if chi2 > 20.5 {
+			t.Fatalf("%s: counts %v aren't uniform, chi2 %f", typ, counts, chi2)
+		}
+
+		for i := 0; i < 1000; i++ {
+			if v := rng.IntRange(-3, 3); v < -3 || v > 3 {
+				t.Fatalf("IntRange(-3, 3) returned %d", v)
+			}
+
+			if v := rng.Int63n(math.MaxInt64); v < 0 {
+				t.Fatalf("Int63n returned %d", v)
+			}
+
+			if v := rng.Uint64n(1<<63 + 1); v > 1<<63 {
+				t.Fatalf("Uint64n returned %d", v)
+			}
+		}
+
+		if rng.IntRange(7, 7) != 7 {
+			t.Fatal("IntRange of a single value")
+		}
+
+		// the whole int range doesn't overflow
+		rng.IntRange(math.MinInt, math.MaxInt)
+	}
+}
+
+func TestTrandInvalidArguments(t *testing.T) {
+	rng := trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_V1)
+
+	for name, f := range map[string]func(){
+		"Intn":     func() { rng.Intn(0) },
+		"Uint64n":  func() { rng.Uint64n(0) },
+		"Int63n":   func() { rng.Int63n(-1) },
+		"IntRange": func() { rng.IntRange(2, 1) },
+		"version":  func() { trand.NewRng(trandSeed(), trand.RNGType_Normal, trand.AlgorithmVersion_Latest+1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expect %s to panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
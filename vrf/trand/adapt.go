@@ -0,0 +1,74 @@
+package trand
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand"
+)
+
+// source64 exposes an Rng as a math/rand.Source64
+type source64 struct {
+	r Rng
+}
+
+// NewSource returns a math/rand.Source64 drawing from r, Uint64 is a draw of r and Int63 is
+// the 63 high bits of a draw. The source can't be reseeded, Seed is a no-op since the seed of r
+// is what makes the draws verifiable, libraries seeding their *rand.Rand keep drawing from r.
+func NewSource(r Rng) rand.Source64 {
+	return &source64{r: r}
+}
+
+func (s *source64) Int63() int64 {
+	return int64(s.r.Uint64() >> 1)
+}
+
+func (s *source64) Uint64() uint64 {
+	return s.r.Uint64()
+}
+
+// Seed is a no-op, the draws keep following r
+func (s *source64) Seed(seed int64) {}
+
+// NewRand returns a math/rand.Rand drawing from r, for libraries taking a *rand.Rand.
+// The mapping of the methods of rand.Rand is the one of the math/rand of the go version
+// the game is built with, values going into replays should be drawn from r directly.
+func NewRand(r Rng) *rand.Rand {
+	return rand.New(NewSource(r))
+}
+
+// reader exposes an Rng as an io.Reader
+type reader struct {
+	r     Rng
+	buf   [8]byte
+	index int // next byte of buf, 8 when buf is used up
+}
+
+// NewReader returns an io.Reader of the little endian bytes of the successive draws of r, the byte
+// order of the ChaCha keystream for cipher generators. The bytes of a draw not returned by a Read
+// are returned by the next one, so the stream doesn't depend on the size of the reads.
+// Read never fails.
+func NewReader(r Rng) io.Reader {
+	return &reader{r: r, index: 8}
+}
+
+func (rd *reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if rd.index == 8 {
+			if len(p)-n >= 8 {
+				binary.LittleEndian.PutUint64(p[n:], rd.r.Uint64())
+				n += 8
+				continue
+			}
+
+			binary.LittleEndian.PutUint64(rd.buf[:], rd.r.Uint64())
+			rd.index = 0
+		}
+
+		c := copy(p[n:], rd.buf[rd.index:])
+		rd.index += c
+		n += c
+	}
+
+	return n, nil
+}
@@ -0,0 +1,138 @@
+package trand
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"golang.org/x/xerrors"
+)
+
+// chacha represents a ChaCha8 or ChaCha20 random number generator, stream 0 of the generators
+// of github.com/nixberg/chacha-rng-go and of the rust crate rand_chacha.
+// The 64 bits block counter is kept so the generator can be stored and seeked.
+type chacha struct {
+	rounds  int
+	key     [8]uint32
+	counter uint64 // next block to generate
+	block   [16]uint32
+	index   int // next word of block, 16 when block is used up
+}
+
+// newChacha creates a new ChaCha random number generator of rounds rounds with the given seed,
+// the seed is read as 8 little endian words
+func newChacha(seed [32]byte, rounds int) *chacha {
+	c := &chacha{rounds: rounds, index: 16}
+	for i := range c.key {
+		c.key[i] = binary.LittleEndian.Uint32(seed[i*4:])
+	}
+
+	return c
+}
+
+// Uint64 generates a random uint64 using ChaCha
+func (c *chacha) Uint64() uint64 {
+	lo := uint64(c.uint32())
+	hi := uint64(c.uint32())
+	return hi<<32 | lo
+}
+
+// Float64 generates a random float64 using ChaCha
+func (c *chacha) Float64() float64 {
+	return float64(c.Uint64()>>11) * 0x1p-53
+}
+
+func (c *chacha) uint32() uint32 {
+	if c.index == 16 {
+		c.generate(c.counter)
+		c.counter++
+		c.index = 0
+	}
+
+	w := c.block[c.index]
+	c.index++
+	return w
+}
+
+// SeekTo positions the generator so the next Uint64 is the n-th one drawn from the seed, counting from 0
+func (c *chacha) SeekTo(n uint64) {
+	// a block holds 8 uint64
+	c.counter = n / 8
+	c.index = 16
+	if words := int(n%8) * 2; words > 0 {
+		c.generate(c.counter)
+		c.counter++
+		c.index = words
+	}
+}
+
+// position returns the number of Uint64 drawn from the seed
+func (c *chacha) position() uint64 {
+	return c.counter*8 - uint64(16-c.index)/2
+}
+
+// chacha state: 32 bytes key, 8 bytes big endian position
+func (c *chacha) marshal() []byte {
+	b := make([]byte, 40)
+	for i, k := range c.key {
+		binary.LittleEndian.PutUint32(b[i*4:], k)
+	}
+	binary.BigEndian.PutUint64(b[32:], c.position())
+
+	return b
+}
+
+func (c *chacha) unmarshal(b []byte) error {
+	if len(b) != 40 {
+		return xerrors.Errorf("invalid chacha state size %d", len(b))
+	}
+
+	for i := range c.key {
+		c.key[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	c.SeekTo(binary.BigEndian.Uint64(b[32:]))
+
+	return nil
+}
+
+// generate computes the block of counter
+func (c *chacha) generate(counter uint64) {
+	state := [16]uint32{
+		0x61707865, 0x3320646e, 0x79622d32, 0x6b206574,
+		c.key[0], c.key[1], c.key[2], c.key[3],
+		c.key[4], c.key[5], c.key[6], c.key[7],
+		uint32(counter), uint32(counter >> 32), 0, 0,
+	}
+
+	x := state
+	for i := 0; i < c.rounds; i += 2 {
+		x[0], x[4], x[8], x[12] = quarterRound(x[0], x[4], x[8], x[12])
+		x[1], x[5], x[9], x[13] = quarterRound(x[1], x[5], x[9], x[13])
+		x[2], x[6], x[10], x[14] = quarterRound(x[2], x[6], x[10], x[14])
+		x[3], x[7], x[11], x[15] = quarterRound(x[3], x[7], x[11], x[15])
+
+		x[0], x[5], x[10], x[15] = quarterRound(x[0], x[5], x[10], x[15])
+		x[1], x[6], x[11], x[12] = quarterRound(x[1], x[6], x[11], x[12])
+		x[2], x[7], x[8], x[13] = quarterRound(x[2], x[7], x[8], x[13])
+		x[3], x[4], x[9], x[14] = quarterRound(x[3], x[4], x[9], x[14])
+	}
+
+	for i := range c.block {
+		c.block[i] = x[i] + state[i]
+	}
+}
+
+func quarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d = bits.RotateLeft32(d^a, 16)
+
+	c += d
+	b = bits.RotateLeft32(b^c, 12)
+
+	a += b
+	d = bits.RotateLeft32(d^a, 8)
+
+	c += d
+	b = bits.RotateLeft32(b^c, 7)
+
+	return a, b, c, d
+}
@@ -0,0 +1,228 @@
+package trand
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// CTR_DRBG_SEED_SIZE is the seedlen of CTR_DRBG with AES-256, the size of the entropy input,
+	// personalization string and additional input
+	CTR_DRBG_SEED_SIZE = ctrDRBGKeySize + aes.BlockSize
+	// CTR_DRBG_MAX_REQUEST is the largest Generate request, 2^19 bits
+	CTR_DRBG_MAX_REQUEST = 1 << 16
+	// CTR_DRBG_RESEED_INTERVAL is the number of Generate requests allowed between reseeds
+	CTR_DRBG_RESEED_INTERVAL = 1 << 48
+
+	ctrDRBGKeySize = 32
+	// ctrDRBGRequest is the size of the requests of the Rng, 8 uint64 per request
+	ctrDRBGRequest = 64
+)
+
+// CtrDRBG is the NIST SP 800-90A Rev. 1 CTR_DRBG with AES-256, a 128 bits counter,
+// no derivation function and no prediction resistance
+type CtrDRBG struct {
+	key           [ctrDRBGKeySize]byte
+	v             [aes.BlockSize]byte
+	block         cipher.Block
+	reseedCounter uint64
+}
+
+// NewCtrDRBG instantiates the DRBG with entropy and an optional personalization string,
+// both CTR_DRBG_SEED_SIZE bytes when given, per section 10.2.1.3.1
+func NewCtrDRBG(entropy, personalization []byte) (*CtrDRBG, error) {
+	seed, err := ctrDRBGSeed(entropy, personalization)
+	if err != nil {
+		return nil, xerrors.Errorf("NewCtrDRBG %w", err)
+	}
+
+	d := &CtrDRBG{}
+	d.setKey(d.key)
+	d.update(seed)
+	d.reseedCounter = 1
+
+	return d, nil
+}
+
+// ctrDRBGSeed returns entropy xor data, data is optional
+func ctrDRBGSeed(entropy, data []byte) (*[CTR_DRBG_SEED_SIZE]byte, error) {
+	if len(entropy) != CTR_DRBG_SEED_SIZE {
+		return nil, xerrors.Errorf("entropy input of %d bytes, expect %d", len(entropy), CTR_DRBG_SEED_SIZE)
+	}
+
+	if len(data) != 0 && len(data) != CTR_DRBG_SEED_SIZE {
+		return nil, xerrors.Errorf("input of %d bytes, expect %d", len(data), CTR_DRBG_SEED_SIZE)
+	}
+
+	var seed [CTR_DRBG_SEED_SIZE]byte
+	copy(seed[:], entropy)
+	for i := range data {
+		seed[i] ^= data[i]
+	}
+
+	return &seed, nil
+}
+
+func (d *CtrDRBG) setKey(key [ctrDRBGKeySize]byte) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		// the key size is fixed
+		panic(err)
+	}
+
+	d.key = key
+	d.block = block
+}
+
+// increment adds 1 to V modulo 2^128
+func (d *CtrDRBG) increment() {
+	lo := binary.BigEndian.Uint64(d.v[8:]) + 1
+	binary.BigEndian.PutUint64(d.v[8:], lo)
+	if lo == 0 {
+		binary.BigEndian.PutUint64(d.v[:8], binary.BigEndian.Uint64(d.v[:8])+1)
+	}
+}
+
+// update is CTR_DRBG_Update, section 10.2.1.2
+func (d *CtrDRBG) update(data *[CTR_DRBG_SEED_SIZE]byte) {
+	var temp [CTR_DRBG_SEED_SIZE]byte
+	for i := 0; i < CTR_DRBG_SEED_SIZE; i += aes.BlockSize {
+		d.increment()
+		d.block.Encrypt(temp[i:], d.v[:])
+	}
+
+	for i := range temp {
+		temp[i] ^= data[i]
+	}
+
+	var key [ctrDRBGKeySize]byte
+	copy(key[:], temp[:ctrDRBGKeySize])
+	copy(d.v[:], temp[ctrDRBGKeySize:])
+	d.setKey(key)
+}
+
+// Reseed reseeds the DRBG with entropy and an optional additional input, per section 10.2.1.4.1
+func (d *CtrDRBG) Reseed(entropy, additional []byte) error {
+	seed, err := ctrDRBGSeed(entropy, additional)
+	if err != nil {
+		return xerrors.Errorf("Reseed %w", err)
+	}
+
+	d.update(seed)
+	d.reseedCounter = 1
+
+	return nil
+}
+
+// Generate fills out with an optional additional input, per section 10.2.1.5.1
+func (d *CtrDRBG) Generate(out, additional []byte) error {
+	if len(out) > CTR_DRBG_MAX_REQUEST {
+		return xerrors.Errorf("Generate request of %d bytes exceeds %d", len(out), CTR_DRBG_MAX_REQUEST)
+	}
+
+	if d.reseedCounter > CTR_DRBG_RESEED_INTERVAL {
+		return xerrors.New("Generate reseed required")
+	}
+
+	var input [CTR_DRBG_SEED_SIZE]byte
+	if len(additional) != 0 {
+		if len(additional) != CTR_DRBG_SEED_SIZE {
+			return xerrors.Errorf("Generate additional input of %d bytes, expect %d", len(additional), CTR_DRBG_SEED_SIZE)
+		}
+		copy(input[:], additional)
+		d.update(&input)
+	}
+
+	var block [aes.BlockSize]byte
+	for i := 0; i < len(out); i += aes.BlockSize {
+		d.increment()
+		d.block.Encrypt(block[:], d.v[:])
+		copy(out[i:], block[:])
+	}
+
+	d.update(&input)
+	d.reseedCounter++
+
+	return nil
+}
+
+// ctrDRBG is the Rng source of a CtrDRBG instantiated with entropy input seed || 16 zero bytes and no
+// personalization string. Every request is 64 bytes without additional input, read as 8 big endian uint64.
+type ctrDRBG struct {
+	d     *CtrDRBG
+	buf   [ctrDRBGRequest]byte
+	index int // next byte of buf, ctrDRBGRequest when buf is used up
+}
+
+func newCtrDRBGSource(seed [32]byte) *ctrDRBG {
+	var entropy [CTR_DRBG_SEED_SIZE]byte
+	copy(entropy[:], seed[:])
+
+	d, err := NewCtrDRBG(entropy[:], nil)
+	if err != nil {
+		// the entropy size is fixed
+		panic(err)
+	}
+
+	return &ctrDRBG{d: d, index: ctrDRBGRequest}
+}
+
+func (c *ctrDRBG) Uint64() uint64 {
+	if c.index == ctrDRBGRequest {
+		err := c.d.Generate(c.buf[:], nil)
+		if err != nil {
+			// 2^48 requests can't be reached by a game
+			panic(err)
+		}
+		c.index = 0
+	}
+
+	v := binary.BigEndian.Uint64(c.buf[c.index:])
+	c.index += 8
+	return v
+}
+
+func (c *ctrDRBG) Float64() float64 {
+	return float64(c.Uint64()>>11) * 0x1p-53
+}
+
+// ctrDRBG state: key, V, big endian reseed counter, 1 byte index and the buffered request
+func (c *ctrDRBG) marshal() []byte {
+	b := append([]byte(nil), c.d.key[:]...)
+	b = append(b, c.d.v[:]...)
+	b = binary.BigEndian.AppendUint64(b, c.d.reseedCounter)
+	b = append(b, byte(c.index))
+
+	return append(b, c.buf[:]...)
+}
+
+func (c *ctrDRBG) unmarshal(b []byte) error {
+	if len(b) != CTR_DRBG_SEED_SIZE+8+1+ctrDRBGRequest {
+		return xerrors.Errorf("invalid ctr drbg state size %d", len(b))
+	}
+
+	index := int(b[CTR_DRBG_SEED_SIZE+8])
+	if index > ctrDRBGRequest || index%8 != 0 {
+		return xerrors.Errorf("invalid ctr drbg buffer index %d", index)
+	}
+
+	reseedCounter := binary.BigEndian.Uint64(b[CTR_DRBG_SEED_SIZE:])
+	if reseedCounter == 0 {
+		return xerrors.Errorf("invalid ctr drbg reseed counter %d", reseedCounter)
+	}
+
+	var key [ctrDRBGKeySize]byte
+	copy(key[:], b)
+	d := &CtrDRBG{reseedCounter: reseedCounter}
+	d.setKey(key)
+	copy(d.v[:], b[ctrDRBGKeySize:CTR_DRBG_SEED_SIZE])
+
+	c.d = d
+	c.index = index
+	copy(c.buf[:], b[CTR_DRBG_SEED_SIZE+9:])
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package trand
+
+import (
+	"math"
+
+	"golang.org/x/xerrors"
+)
+
+// The distributions below are those of AlgorithmVersion_V1, generators of AlgorithmVersion_Legacy use them as well
+// since there were no distributions before. A new algorithm version selects new algorithms through RngV1.Version(),
+// the algorithms of an existing version never change.
+//
+// Uniform floats are built from Uint64 only, never from Float64 whose mapping depends on the generator:
+// u53 = Uint64() >> 11, uniform in [0, 1) is u53 * 2^-53 and uniform in (0, 1] is (u53 + 1) * 2^-53.
+// Apart from arithmetic the algorithms use math.Sqrt, math.Log, math.Exp and math.Lgamma.
+//
+// Every product feeding an addition or a subtraction is rounded with an explicit float64 conversion,
+// otherwise the compiler may fuse them into FMA instructions on arm64, ppc64 and s390x and the draws
+// would differ from amd64. Products returned by helpers or kept in variables are rounded where they are
+// computed, divisions by 2 count as products since they are compiled to multiplications by 0.5.
+
+// uniform returns a float in [0, 1)
+func uniform(r Rng) float64 {
+	return float64(float64(r.Uint64()>>11) * 0x1p-53)
+}
+
+// uniformOpen returns a float in (0, 1]
+func uniformOpen(r Rng) float64 {
+	return float64(float64(r.Uint64()>>11+1) * 0x1p-53)
+}
+
+// NormFloat64 returns a normal distributed float with mean 0 and standard deviation 1, using
+// Marsaglia's polar method: u = 2*uniform-1, v = 2*uniform-1 and s = u*u + v*v until 0 < s < 1,
+// the result is u * sqrt(-2 * log(s) / s), the second normal value v * sqrt(-2 * log(s) / s) is dropped
+func NormFloat64(r Rng) float64 {
+	for {
+		u := float64(2*uniform(r)) - 1
+		v := float64(2*uniform(r)) - 1
+		s := float64(u*u) + float64(v*v)
+		if s > 0 && s < 1 {
+			return u * math.Sqrt(-2*math.Log(s)/s)
+		}
+	}
+}
+
+// ExpFloat64 returns an exponential distributed float with rate 1, -log(uniformOpen)
+func ExpFloat64(r Rng) float64 {
+	return -math.Log(uniformOpen(r))
+}
+
+// Poisson returns a Poisson distributed integer with mean lambda. Below a mean of 10 it multiplies uniforms
+// until the product is at most exp(-lambda), from 10 on it uses Hörmann's transformed rejection PTRS.
+func Poisson(r Rng, lambda float64) int {
+	if lambda < 0 || math.IsNaN(lambda) || math.IsInf(lambda, 0) {
+		panic("trand: invalid argument to Poisson")
+	}
+
+	if lambda == 0 {
+		return 0
+	}
+
+	if lambda < 10 {
+		l := math.Exp(-lambda)
+		k := 0
+		p := uniform(r)
+		for p > l {
+			k++
+			p *= uniform(r)
+		}
+		return k
+	}
+
+	slam := math.Sqrt(lambda)
+	loglam := math.Log(lambda)
+	b := 0.931 + float64(2.53*slam)
+	a := -0.059 + float64(0.02483*b)
+	invalpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+
+	for {
+		u := uniform(r) - 0.5
+		v := uniform(r)
+		us := 0.5 - math.Abs(u)
+		k := math.Floor(float64((2*a/us+b)*u) + lambda + 0.43)
+		if us >= 0.07 && v <= vr {
+			return int(k)
+		}
+
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+
+		lg, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invalpha)-math.Log(a/(us*us)+b) <= -lambda+float64(k*loglam)-lg {
+			return int(k)
+		}
+	}
+}
+
+// Binomial returns the number of successes of n trials with probability p. With r = min(p, 1-p) it
+// uses inversion when n*r < 30 and Kachitvichyanukul and Schmeiser's BTPE otherwise, both draw
+// with probability r and return n minus the draw when p > 0.5.
+func Binomial(r Rng, n int, p float64) int {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		panic("trand: invalid argument to Binomial")
+	}
+
+	if n == 0 || p == 0 {
+		return 0
+	}
+
+	if p == 1 {
+		return n
+	}
+
+	q := math.Min(p, 1-p)
+	var k int
+	if float64(n)*q < 30 {
+		k = binomialInversion(r, n, q)
+	} else {
+		k = binomialBTPE(r, n, q)
+	}
+
+	if p > 0.5 {
+		return n - k
+	}
+
+	return k
+}
+
+// binomialInversion walks the probabilities of 0, 1, ... until the uniform is spent,
+// restarting with a new uniform past mean + 10 standard deviations
+func binomialInversion(r Rng, n int, p float64) int {
+	q := 1 - p
+	qn := math.Exp(float64(n) * math.Log(q))
+	np := float64(float64(n) * p)
+	bound := math.Min(float64(n), np+float64(10*math.Sqrt(float64(np*q)+1)))
+
+	x := 0
+	px := qn
+	u := uniform(r)
+	for u > px {
+		x++
+		if float64(x) > bound {
+			x = 0
+			px = qn
+			u = uniform(r)
+		} else {
+			u -= px
+			px = (float64(n-x+1) * p * px) / (float64(x) * q)
+		}
+	}
+
+	return x
+}
+
+// binomialBTPE is the BTPE algorithm, the steps are numbered as in the paper
+func binomialBTPE(rng Rng, n int, p float64) int {
+	nf := float64(n)
+	r := p
+	q := 1 - r
+	fm := float64(nf*r) + r
+	m := math.Floor(fm)
+	p1 := math.Floor(float64(2.195*math.Sqrt(nf*r*q))-float64(4.6*q)) + 0.5
+	xm := m + 0.5
+	xl := xm - p1
+	xr := xm + p1
+	c := 0.134 + 20.5/(15.3+m)
+	a := (fm - xl) / (fm - float64(xl*r))
+	laml := a * (1 + float64(a/2))
+	a = (xr - fm) / (xr * q)
+	lamr := a * (1 + float64(a/2))
+	p2 := float64(p1 * (1 + float64(2*c)))
+	p3 := p2 + c/laml
+	p4 := p3 + c/lamr
+	nrq := float64(nf * r * q)
+
+	for {
+		// step 1, the triangular region
+		u := float64(uniform(rng) * p4)
+		v := uniform(rng)
+		var y float64
+		if u <= p1 {
+			return int(math.Floor(xm - float64(p1*v) + u))
+		}
+
+		switch {
+		case u <= p2:
+			// step 2, the parallelograms
+			x := xl + (u-p1)/c
+			v = float64(v*c) + 1 - math.Abs(m-x+0.5)/p1
+			if v > 1 {
+				continue
+			}
+			y = math.Floor(x)
+		case u <= p3:
+			// step 3, the left exponential tail
+			y = math.Floor(xl + math.Log(v)/laml)
+			if y < 0 {
+				continue
+			}
+			v = v * (u - p2) * laml
+		default:
+			// step 4, the right exponential tail
+			y = math.Floor(xr - math.Log(v)/lamr)
+			if y > nf {
+				continue
+			}
+			v = v * (u - p3) * lamr
+		}
+
+		// step 5, acceptance
+		k := math.Abs(y - m)
+		if k <= 20 || k >= float64(nrq/2)-1 {
+			// step 5.1, evaluate f(y) recursively
+			s := r / q
+			a := s * (nf + 1)
+			f := 1.0
+			if m < y {
+				for i := m + 1; i <= y; i++ {
+					f *= a/i - s
+				}
+			} else if m > y {
+				for i := y + 1; i <= m; i++ {
+					f /= a/i - s
+				}
+			}
+
+			if v > f {
+				continue
+			}
+			return int(y)
+		}
+
+		// step 5.2, squeeze with the normal bounds of log f(y)
+		rho := float64((k / nrq) * ((float64(k*(k/3+0.625))+0.16666666666666666)/nrq + 0.5))
+		t := -k * k / (2 * nrq)
+		la := math.Log(v)
+		if la < t-rho {
+			return int(y)
+		}
+		if la > t+rho {
+			continue
+		}
+
+		// step 5.3, final comparison with Stirling's formula
+		x1 := y + 1
+		f1 := m + 1
+		z := nf + 1 - m
+		w := nf - y + 1
+		if la > float64(xm*math.Log(f1/x1))+float64((nf-m+0.5)*math.Log(z/w))+float64((y-m)*math.Log(w*r/(x1*q)))+
+			stirling(f1)+stirling(z)+stirling(x1)+stirling(w) {
+			continue
+		}
+
+		return int(y)
+	}
+}
+
+// stirling is the correction term of Stirling's formula used by BTPE
+func stirling(x float64) float64 {
+	x2 := x * x
+	return (13860 - (462-(132-(99-140/x2)/x2)/x2)/x2) / x / 166320
+}
+
+// Geometric returns the number of trials up to and including the first success with probability p,
+// floor(log(uniformOpen) / log(1 - p)) + 1, clamped to math.MaxInt64
+func Geometric(r Rng, p float64) int64 {
+	if !(p > 0 && p <= 1) {
+		panic("trand: invalid argument to Geometric")
+	}
+
+	if p == 1 {
+		return 1
+	}
+
+	k := math.Floor(math.Log(uniformOpen(r))/math.Log1p(-p)) + 1
+	if k >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(k)
+}
+
+// Zipf draws integers k in [0, imax] with probability proportional to (v + k)^(-s),
+// with the rejection inversion method of Hörmann and Derflinger also used by math/rand
+type Zipf struct {
+	imax         float64
+	v            float64
+	q            float64
+	s            float64
+	oneminusQ    float64
+	oneminusQinv float64
+	hxm          float64
+	hx0minusHxm  float64
+}
+
+// NewZipf returns a Zipf distribution, s must be > 1 and v >= 1
+func NewZipf(s, v float64, imax uint64) (*Zipf, error) {
+	if !(s > 1) || !(v >= 1) {
+		return nil, xerrors.Errorf("NewZipf invalid parameters s %f v %f", s, v)
+	}
+
+	z := &Zipf{
+		imax:         float64(imax),
+		v:            v,
+		q:            s,
+		oneminusQ:    1 - s,
+		oneminusQinv: 1 / (1 - s),
+	}
+	z.hxm = z.h(z.imax + 0.5)
+	z.hx0minusHxm = z.h(0.5) - math.Exp(math.Log(z.v)*(-z.q)) - z.hxm
+	z.s = 1 - z.hinv(z.h(1.5)-math.Exp(-z.q*math.Log(z.v+1)))
+
+	return z, nil
+}
+
+func (z *Zipf) h(x float64) float64 {
+	return float64(math.Exp(z.oneminusQ*math.Log(z.v+x)) * z.oneminusQinv)
+}
+
+func (z *Zipf) hinv(x float64) float64 {
+	return math.Exp(z.oneminusQinv*math.Log(z.oneminusQ*x)) - z.v
+}
+
+// Uint64 draws a value of the distribution
+func (z *Zipf) Uint64(r Rng) uint64 {
+	for {
+		ur := z.hxm + float64(uniform(r)*z.hx0minusHxm)
+		x := z.hinv(ur)
+		k := math.Floor(x + 0.5)
+		if k-x <= z.s {
+			return uint64(k)
+		}
+
+		if ur >= z.h(k+0.5)-math.Exp(-math.Log(k+z.v)*z.q) {
+			return uint64(k)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package trand
+
+import (
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/xerrors"
+)
+
+// The helpers below only draw with Uint64n, which doesn't depend on the algorithm version, and their
+// algorithms are specified exactly so they can be reimplemented in other languages with the same output.
+
+// Shuffle shuffles s in place with Fisher-Yates from the end:
+// for i from len(s)-1 down to 1, j = Uint64n(i+1) and s[i] is swapped with s[j]
+func Shuffle[T any](r Rng, s []T) {
+	for i := len(s) - 1; i > 0; i-- {
+		j := int(uint64n(r, uint64(i+1)))
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Perm returns a permutation of [0, n), the identity [0, 1, ..., n-1] shuffled with Shuffle
+func Perm(r Rng, n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	Shuffle(r, p)
+
+	return p
+}
+
+// SampleWithoutReplacement returns k distinct elements of s picked with a partial Fisher-Yates from the front
+// over a copy c of s: for i from 0 to k-1, j = i + Uint64n(len(s)-i), c[i] is swapped with c[j], and c[:k] is returned
+func SampleWithoutReplacement[T any](r Rng, s []T, k int) ([]T, error) {
+	if k < 0 || k > len(s) {
+		return nil, xerrors.Errorf("SampleWithoutReplacement can't pick %d elements out of %d", k, len(s))
+	}
+
+	c := make([]T, len(s))
+	copy(c, s)
+	for i := 0; i < k; i++ {
+		j := i + int(uint64n(r, uint64(len(c)-i)))
+		c[i], c[j] = c[j], c[i]
+	}
+
+	return c[:k], nil
+}
+
+// Alias is a table of Vose's alias method, it picks an index with probability weights[i] / sum(weights)
+// in constant time. It is built with integers only so every language builds the same table.
+type Alias struct {
+	total uint64
+	prob  []uint64
+	alias []int
+}
+
+// NewAlias builds the alias table of weights:
+//
+//	n = len(weights), W = sum(weights), p[i] = weights[i] * n
+//	small and large are stacks of the indexes with p[i] < W and p[i] >= W, pushed in index order
+//	while both are non empty: pop l from small and g from large,
+//	  prob[l] = p[l], alias[l] = g, p[g] = p[g] + p[l] - W, push g on small if p[g] < W else on large
+//	every index left on either stack gets prob = W
+//
+// W * n must fit in an uint64.
+func NewAlias(weights []uint64) (*Alias, error) {
+	n := uint64(len(weights))
+	if n == 0 {
+		return nil, xerrors.New("NewAlias no weights")
+	}
+
+	var total uint64
+	for _, w := range weights {
+		var carry uint64
+		total, carry = bits.Add64(total, w, 0)
+		if carry != 0 {
+			return nil, xerrors.New("NewAlias sum of weights overflows")
+		}
+	}
+
+	if total == 0 {
+		return nil, xerrors.New("NewAlias all weights are zero")
+	}
+
+	if hi, _ := bits.Mul64(total, n); hi != 0 {
+		return nil, xerrors.Errorf("NewAlias sum of weights %d times %d weights overflows", total, n)
+	}
+
+	a := &Alias{
+		total: total,
+		prob:  make([]uint64, n),
+		alias: make([]int, n),
+	}
+
+	p := make([]uint64, n)
+	var small, large []int
+	for i, w := range weights {
+		p[i] = w * n
+		if p[i] < total {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		l := small[len(small)-1]
+		small = small[:len(small)-1]
+		g := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		a.prob[l] = p[l]
+		a.alias[l] = g
+		p[g] = p[g] + p[l] - total
+		if p[g] < total {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+
+	for _, i := range append(small, large...) {
+		a.prob[i] = total
+		a.alias[i] = i
+	}
+
+	return a, nil
+}
+
+// Choose picks an index: i = Uint64n(n), then c = Uint64n(W), i is returned if c < prob[i] else alias[i]
+func (a *Alias) Choose(r Rng) int {
+	i := int(uint64n(r, uint64(len(a.prob))))
+	if uint64n(r, a.total) < a.prob[i] {
+		return i
+	}
+
+	return a.alias[i]
+}
+
+// WeightedChoice picks an index with probability weights[i] / sum(weights), it builds
+// the alias table on every call, games picking many times from the same weights should use NewAlias
+func WeightedChoice(r Rng, weights []uint64) (int, error) {
+	a, err := NewAlias(weights)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.Choose(r), nil
+}
+
+// RollDice rolls n dice of the given number of sides, NdS in dice notation, each die is IntRange(1, sides)
+func RollDice(r Rng, n, sides int) ([]int, error) {
+	if n < 0 || sides < 1 {
+		return nil, xerrors.Errorf("RollDice invalid dice %dd%d", n, sides)
+	}
+
+	rolls := make([]int, n)
+	for i := range rolls {
+		rolls[i] = 1 + int(uint64n(r, uint64(sides)))
+	}
+
+	return rolls, nil
+}
+
+// Card is a card of a standard 52 cards deck, Suit()*13 + Rank()
+type Card uint8
+
+// Suits of a standard deck in the order of StandardDeck
+const (
+	Clubs = iota
+	Diamonds
+	Hearts
+	Spades
+)
+
+const (
+	cardRanks = "23456789TJQKA"
+	cardSuits = "cdhs"
+)
+
+// StandardDeck returns the 52 cards in order, clubs to spades and deuce to ace within a suit
+func StandardDeck() []Card {
+	cards := make([]Card, 52)
+	for i := range cards {
+		cards[i] = Card(i)
+	}
+
+	return cards
+}
+
+// Suit returns Clubs, Diamonds, Hearts or Spades
+func (c Card) Suit() int {
+	return int(c) / 13
+}
+
+// Rank returns 0 for a deuce up to 12 for an ace
+func (c Card) Rank() int {
+	return int(c) % 13
+}
+
+// String returns the rank and suit, "Ah" for the ace of hearts
+func (c Card) String() string {
+	if c >= 52 {
+		return fmt.Sprintf("Card(%d)", uint8(c))
+	}
+
+	return string([]byte{cardRanks[c.Rank()], cardSuits[c.Suit()]})
+}
+
+// Deck deals cards from the top of a shuffled deck
+type Deck[T any] struct {
+	cards []T
+	next  int
+}
+
+// NewDeck shuffles a copy of cards with Shuffle, cards[0] of the shuffled copy is the top of the deck
+func NewDeck[T any](r Rng, cards []T) *Deck[T] {
+	c := make([]T, len(cards))
+	copy(c, cards)
+	Shuffle(r, c)
+
+	return &Deck[T]{cards: c}
+}
+
+// Remaining returns the number of cards left to deal
+func (d *Deck[T]) Remaining() int {
+	return len(d.cards) - d.next
+}
+
+// Deal deals the n cards on top of the deck
+func (d *Deck[T]) Deal(n int) ([]T, error) {
+	if n < 0 || n > d.Remaining() {
+		return nil, xerrors.Errorf("Deal %d cards, %d remaining", n, d.Remaining())
+	}
+
+	cards := d.cards[d.next : d.next+n : d.next+n]
+	d.next += n
+
+	return cards, nil
+}
+
+// DealHands deals n cards to each of players one card at a time, like a dealer around a table:
+// the k-th card of hand p is the card at position k*players+p from the top
+func (d *Deck[T]) DealHands(players, n int) ([][]T, error) {
+	if players < 0 || n < 0 || players*n > d.Remaining() {
+		return nil, xerrors.Errorf("DealHands %d cards to %d players, %d remaining", n, players, d.Remaining())
+	}
+
+	hands := make([][]T, players)
+	for k := 0; k < n; k++ {
+		for p := range hands {
+			hands[p] = append(hands[p], d.cards[d.next])
+			d.next++
+		}
+	}
+
+	return hands, nil
+}
@@ -0,0 +1,80 @@
+package trand
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"golang.org/x/xerrors"
+)
+
+// pcg is the PCG generator with 128 bits of state and the DXSM output function of math/rand/v2,
+// a 128 bits LCG state = state * mul + inc with the multiplier and increment of pcg-cpp.
+// See https://github.com/imneme/pcg-cpp/commit/871d0494ee9c9a7b7c43f753e3d8ca47c26f8005
+type pcg struct {
+	hi uint64
+	lo uint64
+}
+
+// newPCG folds the seed into the 128 bits state, hi = seed[0:8] ^ seed[16:24] and
+// lo = seed[8:16] ^ seed[24:32], read big endian. With seed[16:32] zero the generator
+// is math/rand/v2.NewPCG(seed[0:8], seed[8:16]).
+func newPCG(seed [32]byte) *pcg {
+	return &pcg{
+		hi: binary.BigEndian.Uint64(seed[0:8]) ^ binary.BigEndian.Uint64(seed[16:24]),
+		lo: binary.BigEndian.Uint64(seed[8:16]) ^ binary.BigEndian.Uint64(seed[24:32]),
+	}
+}
+
+func (p *pcg) next() (hi, lo uint64) {
+	const (
+		mulHi = 2549297995355413924
+		mulLo = 4865540595714422341
+		incHi = 6364136223846793005
+		incLo = 1442695040888963407
+	)
+
+	// state = state * mul + inc
+	hi, lo = bits.Mul64(p.lo, mulLo)
+	hi += p.hi*mulLo + p.lo*mulHi
+	lo, c := bits.Add64(lo, incLo, 0)
+	hi, _ = bits.Add64(hi, incHi, c)
+	p.lo = lo
+	p.hi = hi
+	return hi, lo
+}
+
+// Uint64 generates a random uint64 with the DXSM "double xorshift multiply" output of the new state
+func (p *pcg) Uint64() uint64 {
+	hi, lo := p.next()
+
+	const cheapMul = 0xda942042e4dd58b5
+	hi ^= hi >> 32
+	hi *= cheapMul
+	hi ^= hi >> 48
+	hi *= (lo | 1)
+	return hi
+}
+
+// Float64 generates a random float64 in [0, 1) from the 53 high bits of Uint64
+func (p *pcg) Float64() float64 {
+	return float64(p.Uint64()>>11) * 0x1p-53
+}
+
+// pcg state: hi and lo big endian
+func (p *pcg) marshal() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[0:], p.hi)
+	binary.BigEndian.PutUint64(b[8:], p.lo)
+
+	return b
+}
+
+func (p *pcg) unmarshal(b []byte) error {
+	if len(b) != 16 {
+		return xerrors.Errorf("invalid pcg state size %d", len(b))
+	}
+
+	p.hi = binary.BigEndian.Uint64(b[0:])
+	p.lo = binary.BigEndian.Uint64(b[8:])
+	return nil
+}
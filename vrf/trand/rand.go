@@ -1,12 +1,33 @@
 package trand
 
+import (
+	"fmt"
+	"math/bits"
+)
+
 // RNGType represents the type of random number generator
 type RNGType = string
 
 // Constants defining different types of random number generators
 const (
-	RNGType_Normal RNGType = "normal" // Fast random number generator
-	RNGType_Cipher RNGType = "cipher" // Slow, but cryptographically secure random number generator
+	RNGType_Normal RNGType = "normal" // Fast random number generator, xoshiro256**
+	RNGType_Cipher RNGType = "cipher" // Slow, but cryptographically secure random number generator, ChaCha8
+
+	RNGType_PCG64DXSM RNGType = "pcg64-dxsm"      // Fast random number generator, PCG-DXSM of math/rand/v2
+	RNGType_ChaCha20  RNGType = "chacha20"        // Cryptographically secure random number generator with a larger security margin
+	RNGType_CtrDRBG   RNGType = "ctr-drbg-aes256" // NIST SP 800-90A CTR_DRBG with AES-256, for modes requiring an approved DRBG
+)
+
+// AlgorithmVersion selects how random numbers are mapped to ranges, a replay must use the version it was recorded with
+type AlgorithmVersion int
+
+const (
+	// AlgorithmVersion_Legacy maps Intn with int(Float64()*n), which is biased for large n
+	AlgorithmVersion_Legacy AlgorithmVersion = iota
+	// AlgorithmVersion_V1 maps Intn with unbiased rejection sampling
+	AlgorithmVersion_V1
+
+	AlgorithmVersion_Latest = AlgorithmVersion_V1
 )
 
 // Rng is the interface for a random number generator
@@ -16,16 +37,148 @@ type Rng interface {
 	Float64() float64
 }
 
-// NewRng creates a new random number generator based on the specified type and seed
-func NewRng(seed [32]byte, typ RNGType) Rng {
+// RngV1 is an Rng with the range, version and snapshot methods of the generators of NewRng.
+// The helpers of the package take an Rng and draw ranges with Uint64n if it is an RngV1,
+// otherwise with the algorithm of AlgorithmVersion_V1 over Uint64
+type RngV1 interface {
+	Rng
+	// Uint64n returns a uniform integer in [0, n), n must be > 0
+	Uint64n(n uint64) uint64
+	// Int63n returns a uniform integer in [0, n), n must be > 0
+	Int63n(n int64) int64
+	// IntRange returns a uniform integer in [min, max], max must be >= min
+	IntRange(min, max int) int
+	// Version returns the algorithm version of the generator
+	Version() AlgorithmVersion
+	// MarshalBinary returns a snapshot of the generator, UnmarshalRng or UnmarshalBinary restore it
+	MarshalBinary() ([]byte, error)
+	// UnmarshalBinary restores a snapshot of a generator of the same type
+	UnmarshalBinary(data []byte) error
+}
+
+// source is a generator of uniform uint64 and float64 in [0, 1)
+type source interface {
+	Uint64() uint64
+	Float64() float64
+	marshal() []byte
+	unmarshal(b []byte) error
+}
+
+// rng maps the output of a source to ranges according to its algorithm version
+type rng struct {
+	source
+	typ     RNGType
+	version AlgorithmVersion
+}
+
+// NewRng creates a new random number generator based on the specified type and seed.
+// The algorithm version defaults to AlgorithmVersion_Legacy so existing replays reproduce,
+// new games should pass AlgorithmVersion_Latest and record it.
+func NewRng(seed [32]byte, typ RNGType, version ...AlgorithmVersion) RngV1 {
+	v := AlgorithmVersion_Legacy
+	if len(version) > 0 {
+		v = version[0]
+	}
+
+	if v < AlgorithmVersion_Legacy || v > AlgorithmVersion_Latest {
+		panic(fmt.Sprintf("trand: unknown algorithm version %d", v))
+	}
+
 	switch typ {
 	case RNGType_Cipher:
-		return newChacha8(seed)
+		return &cipherRng{rng{source: newChacha(seed, 8), typ: typ, version: v}}
+	case RNGType_ChaCha20:
+		return &cipherRng{rng{source: newChacha(seed, 20), typ: typ, version: v}}
+	case RNGType_PCG64DXSM:
+		return &rng{source: newPCG(seed), typ: typ, version: v}
+	case RNGType_CtrDRBG:
+		return &rng{source: newCtrDRBGSource(seed), typ: typ, version: v}
 	case RNGType_Normal:
 		fallthrough
 	default:
 		r := &xoshiro256{}
 		r.Seed(seed)
-		return r
+		return &normalRng{rng{source: r, typ: RNGType_Normal, version: v}}
+	}
+}
+
+func (r *rng) Version() AlgorithmVersion {
+	return r.version
+}
+
+// Intn generates a random integer in the range [0, n)
+func (r *rng) Intn(n int) int {
+	if r.version == AlgorithmVersion_Legacy {
+		return int(r.Float64() * float64(n))
+	}
+
+	if n <= 0 {
+		panic("trand: invalid argument to Intn")
 	}
+
+	return int(r.Uint64n(uint64(n)))
+}
+
+func (r *rng) Uint64n(n uint64) uint64 {
+	return lemire(r, n)
+}
+
+// uint64n draws a uniform integer in [0, n) with the Uint64n of r if it is an RngV1, so wrappers like
+// Recorder see the draw, otherwise with the same algorithm over its Uint64
+func uint64n(r Rng, n uint64) uint64 {
+	if v, ok := r.(RngV1); ok {
+		return v.Uint64n(n)
+	}
+
+	return lemire(r, n)
+}
+
+// lemire uses Lemire's multiply and reject method, only the low part of the
+// product below 2^64 mod n is rejected so every result is equally likely
+func lemire(r Rng, n uint64) uint64 {
+	if n == 0 {
+		panic("trand: invalid argument to Uint64n")
+	}
+
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+
+	return hi
+}
+
+func (r *rng) Int63n(n int64) int64 {
+	return int63n(r, n)
+}
+
+func (r *rng) IntRange(min, max int) int {
+	return intRange(r, min, max)
+}
+
+// int63n draws a uniform integer in [0, n) with uint64n
+func int63n(r Rng, n int64) int64 {
+	if n <= 0 {
+		panic("trand: invalid argument to Int63n")
+	}
+
+	return int64(uint64n(r, uint64(n)))
+}
+
+// intRange draws a uniform integer in [min, max] with uint64n
+func intRange(r Rng, min, max int) int {
+	if max < min {
+		panic("trand: invalid argument to IntRange")
+	}
+
+	// the span overflows to 0 when the range covers every int
+	span := uint64(max) - uint64(min) + 1
+	if span == 0 {
+		return int(r.Uint64())
+	}
+
+	return min + int(uint64n(r, span))
 }
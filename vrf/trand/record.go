@@ -0,0 +1,449 @@
+package trand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/minio/blake2b-simd"
+	"golang.org/x/xerrors"
+)
+
+// TRANSCRIPT_VERSION is the version of the transcript encoding and of its hash chain
+const TRANSCRIPT_VERSION = 1
+
+const transcriptContext = "titan-game-sdk/trand-transcript/v1"
+
+// DrawMethod is the Rng method of a draw
+type DrawMethod uint8
+
+const (
+	DrawMethod_Uint64 DrawMethod = iota + 1
+	DrawMethod_Float64
+	DrawMethod_Intn
+	DrawMethod_Uint64n
+	DrawMethod_Int63n
+	DrawMethod_IntRange
+)
+
+var drawMethodNames = map[DrawMethod]string{
+	DrawMethod_Uint64:   "Uint64",
+	DrawMethod_Float64:  "Float64",
+	DrawMethod_Intn:     "Intn",
+	DrawMethod_Uint64n:  "Uint64n",
+	DrawMethod_Int63n:   "Int63n",
+	DrawMethod_IntRange: "IntRange",
+}
+
+// drawMethodArgs is the number of arguments of each method
+var drawMethodArgs = map[DrawMethod]int{
+	DrawMethod_Uint64:   0,
+	DrawMethod_Float64:  0,
+	DrawMethod_Intn:     1,
+	DrawMethod_Uint64n:  1,
+	DrawMethod_Int63n:   1,
+	DrawMethod_IntRange: 2,
+}
+
+func (m DrawMethod) String() string {
+	if name, ok := drawMethodNames[m]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("DrawMethod(%d)", uint8(m))
+}
+
+// signed reports whether the arguments and result of the method are signed integers
+func (m DrawMethod) signed() bool {
+	return m == DrawMethod_Intn || m == DrawMethod_Int63n || m == DrawMethod_IntRange
+}
+
+// Draw is a call to an Rng, arguments and result are stored as the bits of their value,
+// two's complement for signed integers and IEEE 754 for Float64
+type Draw struct {
+	Seq    uint64
+	Method DrawMethod
+	Args   []uint64
+	Result uint64
+}
+
+func (d Draw) String() string {
+	format := func(v uint64) string {
+		if d.Method.signed() {
+			return fmt.Sprint(int64(v))
+		}
+		return fmt.Sprint(v)
+	}
+
+	args := ""
+	for i, a := range d.Args {
+		if i > 0 {
+			args += ", "
+		}
+		args += format(a)
+	}
+
+	result := format(d.Result)
+	if d.Method == DrawMethod_Float64 {
+		result = fmt.Sprint(math.Float64frombits(d.Result))
+	}
+
+	return fmt.Sprintf("#%d %s(%s) = %s", d.Seq, d.Method, args, result)
+}
+
+// encode appends the method byte then the uvarint arguments and result
+func (d Draw) encode(b []byte) []byte {
+	b = append(b, byte(d.Method))
+	for _, a := range d.Args {
+		b = binary.AppendUvarint(b, a)
+	}
+	return binary.AppendUvarint(b, d.Result)
+}
+
+// Transcript is the list of draws of a game, Hash chains them so it can be committed in the replay:
+// h0 = blake2b-256(context), h(i+1) = blake2b-256(h(i) || encoded draw i)
+type Transcript struct {
+	Draws []Draw
+	Hash  [32]byte
+}
+
+func transcriptHash(draws []Draw) [32]byte {
+	h := blake2b.Sum256([]byte(transcriptContext))
+	var b []byte
+	for _, d := range draws {
+		b = d.encode(append(b[:0], h[:]...))
+		h = blake2b.Sum256(b)
+	}
+
+	return h
+}
+
+// MarshalBinary encodes the transcript: 1 byte version, uvarint number of draws, then each draw
+// as its method byte followed by its uvarint arguments and result
+func (t *Transcript) MarshalBinary() ([]byte, error) {
+	b := []byte{TRANSCRIPT_VERSION}
+	b = binary.AppendUvarint(b, uint64(len(t.Draws)))
+	for _, d := range t.Draws {
+		b = d.encode(b)
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes a transcript and recomputes its hash, which must be checked against the committed hash
+func (t *Transcript) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	version, err := r.ReadByte()
+	if err != nil {
+		return xerrors.Errorf("UnmarshalBinary %w", err)
+	}
+
+	if version != TRANSCRIPT_VERSION {
+		return xerrors.Errorf("UnmarshalBinary unsupported transcript version %d", version)
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return xerrors.Errorf("UnmarshalBinary %w", err)
+	}
+
+	// a draw takes at least 2 bytes
+	if n > uint64(len(data)/2) {
+		return xerrors.Errorf("UnmarshalBinary %d draws in %d bytes", n, len(data))
+	}
+
+	draws := make([]Draw, n)
+	for i := range draws {
+		m, err := r.ReadByte()
+		if err != nil {
+			return xerrors.Errorf("UnmarshalBinary draw %d: %w", i, err)
+		}
+
+		d := Draw{Seq: uint64(i), Method: DrawMethod(m)}
+		nargs, ok := drawMethodArgs[d.Method]
+		if !ok {
+			return xerrors.Errorf("UnmarshalBinary draw %d: unknown method %d", i, m)
+		}
+
+		for j := 0; j < nargs; j++ {
+			a, err := binary.ReadUvarint(r)
+			if err != nil {
+				return xerrors.Errorf("UnmarshalBinary draw %d: %w", i, err)
+			}
+			d.Args = append(d.Args, a)
+		}
+
+		d.Result, err = binary.ReadUvarint(r)
+		if err != nil {
+			return xerrors.Errorf("UnmarshalBinary draw %d: %w", i, err)
+		}
+		draws[i] = d
+	}
+
+	if r.Len() > 0 {
+		return xerrors.Errorf("UnmarshalBinary %d trailing bytes", r.Len())
+	}
+
+	t.Draws = draws
+	t.Hash = transcriptHash(draws)
+	return nil
+}
+
+// Replay calls the draws of the transcript on r, a fresh generator with the seed of the game,
+// and returns a *DivergenceError at the first draw r doesn't reproduce
+func (t *Transcript) Replay(r Rng) (err error) {
+	// a tampered transcript can hold arguments the generator panics on
+	defer func() {
+		if p := recover(); p != nil {
+			err = xerrors.Errorf("Replay %v", p)
+		}
+	}()
+
+	if transcriptHash(t.Draws) != t.Hash {
+		return xerrors.New("Replay transcript hash doesn't match its draws")
+	}
+
+	for _, expect := range t.Draws {
+		if len(expect.Args) != drawMethodArgs[expect.Method] {
+			return xerrors.Errorf("Replay draw %d: invalid arguments %v", expect.Seq, expect.Args)
+		}
+
+		got := call(r, expect.Seq, expect.Method, expect.Args...)
+		if got.Result != expect.Result {
+			return &DivergenceError{Expect: expect, Got: got}
+		}
+	}
+
+	return nil
+}
+
+// call draws from r with method and records the draw, the range methods of an Rng
+// which isn't an RngV1 are drawn with the algorithm of AlgorithmVersion_V1 over its Uint64
+func call(r Rng, seq uint64, method DrawMethod, args ...uint64) Draw {
+	d := Draw{Seq: seq, Method: method, Args: args}
+	switch method {
+	case DrawMethod_Uint64:
+		d.Result = r.Uint64()
+	case DrawMethod_Float64:
+		d.Result = math.Float64bits(r.Float64())
+	case DrawMethod_Intn:
+		d.Result = uint64(r.Intn(int(args[0])))
+	case DrawMethod_Uint64n:
+		d.Result = uint64n(r, args[0])
+	case DrawMethod_Int63n:
+		d.Result = uint64(int63n(r, int64(args[0])))
+	case DrawMethod_IntRange:
+		d.Result = uint64(intRange(r, int(args[0]), int(args[1])))
+	default:
+		panic(fmt.Sprintf("trand: unknown draw method %d", method))
+	}
+
+	return d
+}
+
+// version returns the version of r, the range draws of an Rng which isn't an RngV1 follow AlgorithmVersion_V1
+func version(r Rng) AlgorithmVersion {
+	if v, ok := r.(RngV1); ok {
+		return v.Version()
+	}
+
+	return AlgorithmVersion_V1
+}
+
+// marshal returns a snapshot of r if it is an RngV1
+func marshal(r Rng) ([]byte, error) {
+	if v, ok := r.(RngV1); ok {
+		return v.MarshalBinary()
+	}
+
+	return nil, xerrors.Errorf("MarshalBinary %T can't be snapshotted", r)
+}
+
+// DivergenceError reports the first draw of a replay not matching its transcript
+type DivergenceError struct {
+	Expect Draw
+	Got    Draw
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("replay diverged at draw %d: transcript %s, replay %s", e.Expect.Seq, e.Expect, e.Got)
+}
+
+// Recorder wraps an Rng and records every draw in a transcript, it is an RngV1 whatever the wrapped Rng
+type Recorder struct {
+	r     Rng
+	draws []Draw
+	hash  [32]byte
+	buf   []byte
+}
+
+// NewRecorder records the draws of r, r must not be used directly while recorded
+func NewRecorder(r Rng) *Recorder {
+	return &Recorder{r: r, hash: blake2b.Sum256([]byte(transcriptContext))}
+}
+
+func (rec *Recorder) record(method DrawMethod, args ...uint64) Draw {
+	d := call(rec.r, uint64(len(rec.draws)), method, args...)
+	rec.draws = append(rec.draws, d)
+
+	rec.buf = d.encode(append(rec.buf[:0], rec.hash[:]...))
+	rec.hash = blake2b.Sum256(rec.buf)
+	return d
+}
+
+// Hash returns the rolling hash of the draws so far
+func (rec *Recorder) Hash() [32]byte {
+	return rec.hash
+}
+
+// Transcript returns the draws so far and their hash
+func (rec *Recorder) Transcript() *Transcript {
+	draws := make([]Draw, len(rec.draws))
+	copy(draws, rec.draws)
+
+	return &Transcript{Draws: draws, Hash: rec.hash}
+}
+
+func (rec *Recorder) Uint64() uint64 {
+	return rec.record(DrawMethod_Uint64).Result
+}
+
+func (rec *Recorder) Float64() float64 {
+	return math.Float64frombits(rec.record(DrawMethod_Float64).Result)
+}
+
+func (rec *Recorder) Intn(n int) int {
+	return int(rec.record(DrawMethod_Intn, uint64(n)).Result)
+}
+
+func (rec *Recorder) Uint64n(n uint64) uint64 {
+	return rec.record(DrawMethod_Uint64n, n).Result
+}
+
+func (rec *Recorder) Int63n(n int64) int64 {
+	return int64(rec.record(DrawMethod_Int63n, uint64(n)).Result)
+}
+
+func (rec *Recorder) IntRange(min, max int) int {
+	return int(rec.record(DrawMethod_IntRange, uint64(min), uint64(max)).Result)
+}
+
+// Version returns the version of the recorded generator, AlgorithmVersion_V1 if it isn't an RngV1
+func (rec *Recorder) Version() AlgorithmVersion {
+	return version(rec.r)
+}
+
+// MarshalBinary returns a snapshot of the recorded generator, which must be an RngV1
+func (rec *Recorder) MarshalBinary() ([]byte, error) {
+	return marshal(rec.r)
+}
+
+// UnmarshalBinary fails, restoring the generator would break the transcript
+func (rec *Recorder) UnmarshalBinary(data []byte) error {
+	return xerrors.New("UnmarshalBinary can't restore a recorded generator")
+}
+
+// Verifier wraps an Rng and checks every draw against a transcript, so the game code can be run
+// again over a fresh generator. Err returns the first divergence, draws after it aren't checked.
+type Verifier struct {
+	r   Rng
+	t   *Transcript
+	seq uint64
+	err error
+}
+
+// NewVerifier checks the draws of r against t
+func NewVerifier(r Rng, t *Transcript) *Verifier {
+	v := &Verifier{r: r, t: t}
+	if transcriptHash(t.Draws) != t.Hash {
+		v.err = xerrors.New("Verifier transcript hash doesn't match its draws")
+	}
+
+	return v
+}
+
+func (v *Verifier) check(method DrawMethod, args ...uint64) Draw {
+	got := call(v.r, v.seq, method, args...)
+	if v.err == nil {
+		if v.seq >= uint64(len(v.t.Draws)) {
+			v.err = xerrors.Errorf("Verifier draw %d isn't in the transcript: %s", v.seq, got)
+		} else if expect := v.t.Draws[v.seq]; expect.Method != got.Method || !equalArgs(expect.Args, got.Args) || expect.Result != got.Result {
+			v.err = &DivergenceError{Expect: expect, Got: got}
+		}
+	}
+	v.seq++
+
+	return got
+}
+
+func equalArgs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Err returns the first divergence
+func (v *Verifier) Err() error {
+	return v.err
+}
+
+// Finish returns the first divergence, or an error if draws of the transcript weren't made
+func (v *Verifier) Finish() error {
+	if v.err != nil {
+		return v.err
+	}
+
+	if v.seq < uint64(len(v.t.Draws)) {
+		return xerrors.Errorf("Finish %d draws of the transcript weren't made", uint64(len(v.t.Draws))-v.seq)
+	}
+
+	return nil
+}
+
+func (v *Verifier) Uint64() uint64 {
+	return v.check(DrawMethod_Uint64).Result
+}
+
+func (v *Verifier) Float64() float64 {
+	return math.Float64frombits(v.check(DrawMethod_Float64).Result)
+}
+
+func (v *Verifier) Intn(n int) int {
+	return int(v.check(DrawMethod_Intn, uint64(n)).Result)
+}
+
+func (v *Verifier) Uint64n(n uint64) uint64 {
+	return v.check(DrawMethod_Uint64n, n).Result
+}
+
+func (v *Verifier) Int63n(n int64) int64 {
+	return int64(v.check(DrawMethod_Int63n, uint64(n)).Result)
+}
+
+func (v *Verifier) IntRange(min, max int) int {
+	return int(v.check(DrawMethod_IntRange, uint64(min), uint64(max)).Result)
+}
+
+// Version returns the version of the verified generator, AlgorithmVersion_V1 if it isn't an RngV1
+func (v *Verifier) Version() AlgorithmVersion {
+	return version(v.r)
+}
+
+// MarshalBinary returns a snapshot of the verified generator, which must be an RngV1
+func (v *Verifier) MarshalBinary() ([]byte, error) {
+	return marshal(v.r)
+}
+
+// UnmarshalBinary fails, restoring the generator would break the verification
+func (v *Verifier) UnmarshalBinary(data []byte) error {
+	return xerrors.New("UnmarshalBinary can't restore a verified generator")
+}
@@ -0,0 +1,117 @@
+package trand
+
+import (
+	"golang.org/x/xerrors"
+)
+
+// SNAPSHOT_VERSION is the version of the snapshot format:
+// 1 byte snapshot version, 1 byte algorithm version, 1 byte length of the RNGType followed by the RNGType,
+// then the state of the generator. RNGType_Normal stores the 4 state words of xoshiro256** big endian,
+// RNGType_Cipher and RNGType_ChaCha20 store the ChaCha key little endian followed by the big endian number
+// of Uint64 drawn, RNGType_PCG64DXSM stores its 128 bits state big endian and RNGType_CtrDRBG stores
+// its key, V, big endian reseed counter, the index and content of its buffered request.
+const SNAPSHOT_VERSION = 1
+
+// Jumper is implemented by the generators of RNGType_Normal with the xoshiro256** jump functions
+type Jumper interface {
+	// Jump advances the generator by 2^128 calls to Uint64
+	Jump()
+	// LongJump advances the generator by 2^192 calls to Uint64
+	LongJump()
+}
+
+// Seeker is implemented by the generators of RNGType_Cipher and RNGType_ChaCha20 with the ChaCha block counter
+type Seeker interface {
+	// SeekTo positions the generator so the next Uint64 is the n-th one drawn from the seed, counting from 0
+	SeekTo(n uint64)
+}
+
+type normalRng struct {
+	rng
+}
+
+func (r *normalRng) Jump() {
+	r.source.(*xoshiro256).Jump()
+}
+
+func (r *normalRng) LongJump() {
+	r.source.(*xoshiro256).LongJump()
+}
+
+type cipherRng struct {
+	rng
+}
+
+func (r *cipherRng) SeekTo(n uint64) {
+	r.source.(*chacha).SeekTo(n)
+}
+
+func (r *rng) MarshalBinary() ([]byte, error) {
+	b := []byte{SNAPSHOT_VERSION, byte(r.version), byte(len(r.typ))}
+	b = append(b, r.typ...)
+
+	return append(b, r.marshal()...), nil
+}
+
+func (r *rng) UnmarshalBinary(data []byte) error {
+	typ, version, state, err := parseSnapshot(data)
+	if err != nil {
+		return err
+	}
+
+	if typ != r.typ {
+		return xerrors.Errorf("UnmarshalBinary snapshot of a %s generator into a %s generator", typ, r.typ)
+	}
+
+	err = r.unmarshal(state)
+	if err != nil {
+		return xerrors.Errorf("UnmarshalBinary %w", err)
+	}
+
+	r.version = version
+	return nil
+}
+
+// UnmarshalRng restores a generator from a snapshot returned by its MarshalBinary
+func UnmarshalRng(data []byte) (RngV1, error) {
+	typ, version, _, err := parseSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case RNGType_Normal, RNGType_Cipher, RNGType_ChaCha20, RNGType_PCG64DXSM, RNGType_CtrDRBG:
+	default:
+		return nil, xerrors.Errorf("UnmarshalRng unknown generator type %s", typ)
+	}
+
+	r := NewRng([32]byte{}, typ, version)
+	err = r.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func parseSnapshot(data []byte) (typ RNGType, version AlgorithmVersion, state []byte, err error) {
+	if len(data) < 3 {
+		return "", 0, nil, xerrors.New("snapshot too short")
+	}
+
+	if data[0] != SNAPSHOT_VERSION {
+		return "", 0, nil, xerrors.Errorf("unsupported snapshot version %d", data[0])
+	}
+
+	version = AlgorithmVersion(data[1])
+	if version > AlgorithmVersion_Latest {
+		return "", 0, nil, xerrors.Errorf("unknown algorithm version %d", version)
+	}
+
+	n := int(data[2])
+	if len(data) < 3+n {
+		return "", 0, nil, xerrors.New("snapshot too short")
+	}
+
+	return RNGType(data[3 : 3+n]), version, data[3+n:], nil
+}
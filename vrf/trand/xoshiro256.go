@@ -10,6 +10,14 @@ package trand
 import (
 	"encoding/binary"
 	"math"
+
+	"golang.org/x/xerrors"
+)
+
+// jump polynomials of the reference implementation
+var (
+	xoshiroJump     = [4]uint64{0x180ec6d33cfd0aba, 0xd5a61266f0c9392c, 0xa9582618e03fc9aa, 0x39abdc4529b1661c}
+	xoshiroLongJump = [4]uint64{0x76e15d3efefdcbbf, 0xc5004e441c522fb3, 0x77710069854ee241, 0x39109bb02acbe635}
 )
 
 type xoshiro256 struct {
@@ -40,14 +48,61 @@ func (s *xoshiro256) Uint64() uint64 {
 	return result
 }
 
-func (s *xoshiro256) Intn(n int) int {
-	return int(s.Float64() * float64(n))
-}
-
 func (s *xoshiro256) Float64() float64 {
 	return float64(s.Uint64()) / (float64(math.MaxUint64) + 1)
 }
 
+// Jump advances the generator by 2^128 calls to Uint64, it can be used to
+// generate 2^128 non-overlapping subsequences
+func (s *xoshiro256) Jump() {
+	s.jump(&xoshiroJump)
+}
+
+// LongJump advances the generator by 2^192 calls to Uint64, it can be used to
+// generate 2^64 starting points from each of which Jump generates 2^64 subsequences
+func (s *xoshiro256) LongJump() {
+	s.jump(&xoshiroLongJump)
+}
+
+func (s *xoshiro256) jump(poly *[4]uint64) {
+	var state [4]uint64
+	for _, p := range poly {
+		for b := 0; b < 64; b++ {
+			if p&(1<<b) != 0 {
+				state[0] ^= s.state[0]
+				state[1] ^= s.state[1]
+				state[2] ^= s.state[2]
+				state[3] ^= s.state[3]
+			}
+			s.Uint64()
+		}
+	}
+
+	s.state = state
+}
+
+// xoshiro256 state: the 4 words big endian, like the seed
+func (s *xoshiro256) marshal() []byte {
+	b := make([]byte, 32)
+	for i, w := range s.state {
+		binary.BigEndian.PutUint64(b[i*8:], w)
+	}
+
+	return b
+}
+
+func (s *xoshiro256) unmarshal(b []byte) error {
+	if len(b) != 32 {
+		return xerrors.Errorf("invalid xoshiro256 state size %d", len(b))
+	}
+
+	var seed [32]byte
+	copy(seed[:], b)
+	s.Seed(seed)
+
+	return nil
+}
+
 func rotl(x uint64, k int) uint64 {
 	return (x << k) | (x >> (64 - k))
 }